@@ -0,0 +1,46 @@
+package txtopener
+
+import "fmt"
+
+// EncodingNotAllowedError is returned by the readers created WithAllowedEncodings when the
+// encoding of the input isn't one of those allowed
+type EncodingNotAllowedError struct {
+	// Encoding is the name of the encoding detected
+	Encoding string
+}
+
+func (e *EncodingNotAllowedError) Error() string {
+	return fmt.Sprintf("txtopener: encoding %s not allowed", e.Encoding)
+}
+
+// WithAllowedEncodings makes the reader fail with an *EncodingNotAllowedError unless the encoding
+// of the input, however it is determined, is one of those labeled labels. Uploads from untrusted
+// sources can so be kept from using exotic encodings to smuggle content past filters.
+// Unknown labels are ignored
+func WithAllowedEncodings(labels ...string) Option {
+	return func(o *options) {
+		if o.allowed == nil {
+			o.allowed = map[string]bool{}
+		}
+		for _, label := range labels {
+			_, name, err := lookup(label)
+			if err != nil {
+				continue
+			}
+			o.allowed[name] = true
+			if name == "windows-1252" {
+				// the labels of ISO-8859-1 are aliases of windows-1252, which covers the
+				// fallback encoding
+				o.allowed["ISO 8859-1"] = true
+			}
+		}
+	}
+}
+
+// checkAllowed returns an error if the encoding name isn't allowed by o
+func checkAllowed(name string, o *options) error {
+	if o.allowed != nil && !o.allowed[name] {
+		return &EncodingNotAllowedError{Encoding: name}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package txtopener
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithAllowedEncodings(t *testing.T) {
+	allowed := WithAllowedEncodings("utf-8", "utf-16le", "latin1", "no-such-encoding")
+	var tests = []struct {
+		feed    string
+		allowed bool
+	}{
+		{"añejo", true},
+		{"\xff\xfea\x00", true},
+		{"caf\xe9", true},
+		{"hola", true},
+		{"\xfe\xff\x00a", false},
+		{`<meta charset="shift_jis">` + "\x83e\x83X\x83g", false},
+	}
+
+	for i, tt := range tests {
+		_, err := NewReaderOpts(strings.NewReader(tt.feed), allowed)
+		var notAllowed *EncodingNotAllowedError
+		if tt.allowed && err != nil || !tt.allowed && !errors.As(err, &notAllowed) {
+			t.Errorf("%d. feeded: %q -> got: %v - expected allowed: %v", i, tt.feed, err, tt.allowed)
+		}
+	}
+}
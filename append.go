@@ -0,0 +1,157 @@
+package txtopener
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// AppendFile appends the UTF-8 data to the file named path re-encoded to match the file's existing
+// encoding and newline style, so appending never produces a mixed-encoding file.
+// A BOM at the start of data is dropped since the file already carries its own, if any.
+// Files that don't exist or are empty are created/written with data as is
+func AppendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+
+	out, err := matchFile(f, data)
+	if err == nil {
+		_, err = f.Write(out)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OpenAppend opens the file named path for appending, creating it if it doesn't exist, and
+// returns a writer that converts the UTF-8 text written to it to the file's existing encoding
+// and newline style, as AppendFile does. Closing the writer flushes it and closes the file
+func OpenAppend(path string) (io.WriteCloser, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	e, newline, err := fileStyle(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &appendWriter{transform.NewWriter(f, appendTransformer(e, newline)), f}, nil
+}
+
+// appendTransformer returns the transformer converting UTF-8 text to the encoding e and the
+// newline sequence of a file, as fileStyle reports them
+func appendTransformer(e encoding.Encoding, newline string) transform.Transformer {
+	// an empty file gets the text as is
+	if e == nil {
+		return transform.Nop
+	}
+	t := transform.Chain(&dropBOM{}, newlines{newline: []byte(newline)})
+	if e != encoding.Nop {
+		t = transform.Chain(t, e.NewEncoder())
+	}
+	return t
+}
+
+// appendWriter is the writer of OpenAppend
+type appendWriter struct {
+	*transform.Writer
+	f *os.File
+}
+
+func (a *appendWriter) Close() error {
+	err := a.Writer.Close()
+	if cerr := a.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// newlines is a transformer that writes newline in place of every LF and CRLF, and of every
+// lone CR as well if cr
+type newlines struct {
+	transform.NopResetter
+	newline []byte
+	cr      bool
+}
+
+func (nl newlines) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c, size, out := src[nSrc], 1, src[nSrc:nSrc+1]
+		switch {
+		case c == '\r' && nSrc+1 == len(src) && !atEOF:
+			return nDst, nSrc, transform.ErrShortSrc
+		case c == '\r' && nSrc+1 < len(src) && src[nSrc+1] == '\n':
+			size, out = 2, nl.newline
+		case c == '\n', c == '\r' && nl.cr:
+			out = nl.newline
+		}
+		if nDst+len(out) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// matchFile returns data converted to the encoding and newline style found at the start of f
+func matchFile(f io.ReaderAt, data []byte) ([]byte, error) {
+	e, newline, err := fileStyle(f)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := transform.Bytes(appendTransformer(e, newline), data)
+	if err != nil {
+		return nil, fmt.Errorf("txtopener: can't append to a %s file: %v", e, err)
+	}
+	return out, nil
+}
+
+// fileStyle examines the preview of f and reports its encoding and newline sequence.
+// A nil encoding means f is empty
+func fileStyle(f io.ReaderAt) (e encoding.Encoding, newline string, err error) {
+	o := newOptions(nil)
+	preview := make([]byte, o.preview())
+	n, err := f.ReadAt(preview, 0)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+	preview = preview[:n]
+	if n == 0 {
+		return nil, "\n", nil
+	}
+
+	e, _, certain := determineEncoding(preview, o)
+	if !certain && isASCII(preview) {
+		// nothing tells a pure ASCII file apart from a Latin-1 one, UTF-8 keeps it ASCII as well
+		e = encoding.Nop
+	}
+
+	newline = "\n"
+	decoded, _ := e.NewDecoder().Bytes(preview)
+	if i := bytes.IndexAny(decoded, "\r\n"); i >= 0 && decoded[i] == '\r' {
+		newline = "\r"
+		if i+1 < len(decoded) && decoded[i+1] == '\n' {
+			newline = "\r\n"
+		}
+	}
+	return e, newline, nil
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
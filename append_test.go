@@ -0,0 +1,89 @@
+package txtopener
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendFile(t *testing.T) {
+	var tests = []struct {
+		existing string
+		data     string
+		expected string
+	}{
+		{"", "pingüino\n", "pingüino\n"},
+		{"abc\n", "pingüino\n", "abc\npingüino\n"},
+		{"fa\xe7ade\r\n", "pingüino\n", "fa\xe7ade\r\nping\xfcino\r\n"},
+		{string(utf8bom) + "a\n", string(utf8bom) + "ü\n", string(utf8bom) + "a\nü\n"},
+		{string(utf16lebom) + "a\x00\r\x00\n\x00", "ü\n", string(utf16lebom) + "a\x00\r\x00\n\x00\xfc\x00\r\x00\n\x00"},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "append.txt")
+		os.Remove(name)
+		if tt.existing != "" {
+			if err := os.WriteFile(name, []byte(tt.existing), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := AppendFile(name, []byte(tt.data)); err != nil {
+			t.Errorf("%d. error en AppendFile: %v", i, err)
+			continue
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. appended: %q -> got: %q - expected: %q", i, tt.data, got, tt.expected)
+		}
+	}
+}
+
+func TestOpenAppend(t *testing.T) {
+	var tests = []struct {
+		existing string
+		data     []string
+		expected string
+	}{
+		{"", []string{string(utf8bom) + "pingüino\r\n"}, string(utf8bom) + "pingüino\r\n"},
+		{"abc\n", []string{"ping", "üino\r\n"}, "abc\npingüino\n"},
+		{"fa\xe7ade\r\n", []string{"pingüino\r", "\nfin\n"}, "fa\xe7ade\r\nping\xfcino\r\nfin\r\n"},
+		{"mac\r", []string{"a\nb\r\n"}, "mac\ra\rb\r"},
+		{string(utf8bom) + "a\n", []string{string(utf8bom) + "ü\n"}, string(utf8bom) + "a\nü\n"},
+		{string(utf16lebom) + "a\x00\r\x00\n\x00", []string{"ü\n"}, string(utf16lebom) + "a\x00\r\x00\n\x00\xfc\x00\r\x00\n\x00"},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "append.txt")
+		os.Remove(name)
+		if tt.existing != "" {
+			if err := os.WriteFile(name, []byte(tt.existing), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w, err := OpenAppend(name)
+		if err != nil {
+			t.Errorf("%d. error en OpenAppend: %v", i, err)
+			continue
+		}
+		for _, s := range tt.data {
+			if _, err := w.Write([]byte(s)); err != nil {
+				t.Errorf("%d. error en Write: %v", i, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. appended: %q -> got: %q - expected: %q", i, tt.data, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+)
+
+// DetectAsync is like NewReader but it doesn't wait for the preview to be read: the returned
+// reader passes the ASCII text at the start of r through as soon as it arrives, as it reads the
+// same in every encoding detected, and decides the encoding at the first byte that isn't ASCII,
+// when the preview fills up or at the end of r. The channel delivers the report of the decision
+// once it is made, and is closed then or when reading r fails before.
+// The decision is made as the reader is read
+func DetectAsync(r io.Reader) (<-chan Report, io.Reader) {
+	ch := make(chan Report, 1)
+	return ch, &asyncReader{r: r, o: newOptions(nil), ch: ch}
+}
+
+// asyncReader is the reader of DetectAsync
+type asyncReader struct {
+	r  io.Reader
+	o  *options
+	ch chan Report
+
+	// buf holds what has been read from r while undecided, and passed how much of it was returned
+	buf    []byte
+	passed int
+	// out is the decoded rest of the content, once decided
+	out io.Reader
+	err error
+}
+
+func (a *asyncReader) Read(p []byte) (int, error) {
+	for a.out == nil {
+		if a.err != nil {
+			return 0, a.err
+		}
+		// the UTF-7 BOM is ASCII, it has to be seen whole before anything passes through
+		if a.passed == 0 && hasUTF7Signature(a.buf) {
+			a.decide()
+			break
+		}
+		maybeUTF7 := a.passed == 0 && len(a.buf) <= len(utf7Signature) && bytes.HasPrefix(utf7Signature, a.buf)
+		// the last byte is held back, a NUL after it would tell UTF-16
+		if n := asciiPrefix(a.buf[a.passed:]); n > 1 && !maybeUTF7 {
+			n = copy(p, a.buf[a.passed:a.passed+n-1])
+			a.passed += n
+			return n, nil
+		}
+		if !maybeUTF7 && a.passed < len(a.buf)-1 || len(a.buf) >= a.o.preview() {
+			a.decide()
+			break
+		}
+
+		chunk := make([]byte, 512)
+		n, err := a.r.Read(chunk)
+		a.buf = append(a.buf, chunk[:n]...)
+		if err == io.EOF {
+			a.decide()
+		} else if err != nil {
+			a.err = err
+			close(a.ch)
+		}
+	}
+	return a.out.Read(p)
+}
+
+// decide reads the rest of the preview, determines the encoding and reports it
+func (a *asyncReader) decide() {
+	if len(a.buf) < a.o.preview() {
+		rest := make([]byte, a.o.preview()-len(a.buf))
+		n, err := io.ReadFull(a.r, rest)
+		a.buf = append(a.buf, rest[:n]...)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			a.err = err
+			close(a.ch)
+			a.out = &errReader{err}
+			return
+		}
+	}
+
+	e, res, _ := newResult(a.buf, a.o)
+	a.ch <- res.report()
+	close(a.ch)
+
+	a.out = decoder(io.MultiReader(bytes.NewReader(a.buf[a.passed:]), a.r), e, res.Encoding, a.o)
+	if a.passed == 0 {
+		out, err := stripBOM(a.out)
+		if err != nil {
+			out = &errReader{err}
+		}
+		a.out = out
+	}
+}
+
+// asciiPrefix returns the length of the text at the start of b that reads the same in every
+// encoding detected: ASCII but NULs and escapes, which start the sequences of ISO-2022
+func asciiPrefix(b []byte) int {
+	for i, c := range b {
+		if c >= 0x80 || c == 0 || c == 0x1b {
+			return i
+		}
+	}
+	return len(b)
+}
+
+// errReader is an io.Reader that fails with err
+type errReader struct{ err error }
+
+func (e *errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,68 @@
+package txtopener
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDetectAsync(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+		report   Report
+	}{
+		{"", "", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+		{"hola", "hola", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+		{"\xef\xbb\xbfañejo", "añejo", Report{Encoding: "utf-8", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{"\xff\xfea\x00\xf1\x00o\x00", "año", Report{Encoding: "utf-16le", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{"una ca\xf1ada", "una cañada", Report{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+		{`<meta charset="koi8-r">` + "\xf0\xd2\xc9\xd7\xc5\xd4", `<meta charset="koi8-r">Привет`, Report{Encoding: "koi8-r", Confidence: 0.9, Evidence: EvidenceMeta}},
+	}
+
+	for i, tt := range tests {
+		ch, r := DetectAsync(iotest.OneByteReader(strings.NewReader(tt.feed)))
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%d. error en ReadAll: %v", i, err)
+			continue
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		if report := <-ch; report != tt.report {
+			t.Errorf("%d. feeded: %q -> got: %+v - expected: %+v", i, tt.feed, report, tt.report)
+		}
+	}
+}
+
+func TestDetectAsyncPassthrough(t *testing.T) {
+	pr, pw := io.Pipe()
+	ch, r := DetectAsync(pr)
+	go pw.Write([]byte("GET / HTTP/1.1\r\n"))
+
+	buf := make([]byte, 64)
+	n, err := r.Read(buf)
+	if err != nil || string(buf[:n]) != "GET / HTTP/1.1\r" {
+		t.Errorf("got: %q, %v - expected: %q", buf[:n], err, "GET / HTTP/1.1\r")
+	}
+	select {
+	case report := <-ch:
+		t.Errorf("unexpected report before the end of the ASCII text: %+v", report)
+	default:
+	}
+
+	go func() {
+		pw.Write([]byte("caf\xe9"))
+		pw.Close()
+	}()
+	rest, err := ioutil.ReadAll(r)
+	if err != nil || string(rest) != "\ncafé" {
+		t.Errorf("got: %q, %v - expected: %q", rest, err, "\ncafé")
+	}
+	if report, ok := <-ch; !ok || report.Encoding != "ISO 8859-1" {
+		t.Errorf("got: %+v, %v - expected the ISO 8859-1 report", report, ok)
+	}
+}
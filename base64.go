@@ -0,0 +1,125 @@
+package txtopener
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+)
+
+// WithBase64 makes the reader look for input that is entirely Base64, as the bodies of many
+// message queues and webhooks are. When found, the Base64 layer is decoded first and then the
+// charset of the text it wraps is detected as usual.
+// Both the standard and the URL alphabets are recognized, with or without padding and line breaks
+func WithBase64() Option {
+	return func(o *options) {
+		o.base64 = true
+	}
+}
+
+// minBase64 is the shortest payload taken for Base64, shorter ones are too likely plain words
+const minBase64 = 16
+
+// base64Encoding returns the Base64 encoding preview is written with, nil if it doesn't look
+// like Base64 wrapping text. complete tells that preview is the whole input
+func base64Encoding(preview []byte, complete bool) *base64.Encoding {
+	clean := make([]byte, 0, len(preview))
+	for _, line := range bytes.Split(preview, []byte("\n")) {
+		clean = append(clean, bytes.TrimRight(line, "\r")...)
+	}
+	if len(clean) < minBase64 {
+		return nil
+	}
+
+	e, std, url := base64.StdEncoding, false, false
+	for i, c := range clean {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9':
+		case c == '+' || c == '/':
+			std = true
+		case c == '-' || c == '_':
+			url = true
+			e = base64.URLEncoding
+		case c == '=' && len(clean)-i <= 2 && complete:
+		default:
+			return nil
+		}
+	}
+	if std && url {
+		return nil
+	}
+
+	// the decoded text has to look like text
+	n := len(clean) / 4 * 4
+	if complete {
+		n = len(clean)
+	}
+	inner, err := io.ReadAll(base64.NewDecoder(e, &base64Text{r: bytes.NewReader(clean[:n])}))
+	if err != nil || !looksLikeText(inner) {
+		return nil
+	}
+	return e
+}
+
+// looksLikeText tells whether b, the start of a decoded payload, isn't binary: it starts with a
+// BOM or it holds no control characters but tabs, line breaks and form feeds
+func looksLikeText(b []byte) bool {
+	for _, bom := range boms {
+		if bytes.HasPrefix(b, bom.bom) {
+			return true
+		}
+	}
+	for _, c := range b {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' && c != '\f' || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// base64Text is the Base64 text of r without its line breaks and padded at the end,
+// as the decoders of encoding/base64 expect it
+type base64Text struct {
+	r      io.Reader
+	n      int
+	padded bool
+	pad    []byte
+	eof    bool
+}
+
+func (t *base64Text) Read(p []byte) (int, error) {
+	for {
+		if t.eof {
+			n := copy(p, t.pad)
+			t.pad = t.pad[n:]
+			if len(t.pad) == 0 {
+				return n, io.EOF
+			}
+			return n, nil
+		}
+
+		n, err := t.r.Read(p)
+		m := 0
+		for _, c := range p[:n] {
+			if c == '\r' || c == '\n' {
+				continue
+			}
+			if c == '=' {
+				t.padded = true
+			}
+			p[m] = c
+			m++
+		}
+		t.n += m
+		if err == io.EOF {
+			t.eof = true
+			if rest := t.n % 4; rest != 0 && !t.padded {
+				t.pad = bytes.Repeat([]byte("="), 4-rest)
+			}
+		} else if err != nil {
+			return m, err
+		}
+		if m > 0 {
+			return m, nil
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package txtopener
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestWithBase64(t *testing.T) {
+	long := strings.Repeat("ping\xfcino ", 2000)
+	wrapped := base64.StdEncoding.EncodeToString([]byte(long))
+	for i := 76; i < len(wrapped); i += 78 {
+		wrapped = wrapped[:i] + "\r\n" + wrapped[i:]
+	}
+
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{base64.StdEncoding.EncodeToString([]byte("El ping\xfcino come pescado")), "El pingüino come pescado"},
+		{base64.RawURLEncoding.EncodeToString([]byte("\xef\xbb\xbfpingüino ~~ ??")), "pingüino ~~ ??"},
+		{base64.StdEncoding.EncodeToString([]byte("\xff\xfep\x00i\x00n\x00g\x00\xfc\x00i\x00n\x00o\x00")), "pingüino"},
+		{wrapped, strings.Repeat("pingüino ", 2000)},
+		{"ThisIsAVeryLongIdentifier", "ThisIsAVeryLongIdentifier"},
+		{"short", "short"},
+		{"caf\xe9 au lait", "café au lait"},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), WithBase64())
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%d. error en ReadAll: %v", i, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %.40q -> got: %.40q - expected: %.40q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package txtopener
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrBinaryFile is returned by the readers created WithRejectBinary when the input looks binary
+var ErrBinaryFile = errors.New("txtopener: binary file")
+
+// WithRejectBinary makes the reader fail with ErrBinaryFile instead of decoding input that IsBinary
+// tells binary, such as images and executables, which would read as garbage text otherwise.
+// Input told to be UTF-16 or UTF-32 by a Content-Type or a detector is text nonetheless
+func WithRejectBinary() Option {
+	return func(o *options) {
+		o.rejectBinary = true
+	}
+}
+
+// checkBinary returns an error if binary input is rejected and preview, detected as the encoding
+// named name, looks binary
+func checkBinary(preview []byte, name string, o *options) error {
+	if !o.rejectBinary || strings.HasPrefix(name, "utf-16") || strings.HasPrefix(name, "utf-32") {
+		return nil
+	}
+	if IsBinary(preview) {
+		return ErrBinaryFile
+	}
+	return nil
+}
+
+// thresholds of IsBinary
+const (
+	// binaryNULs is the share of NUL bytes past which content is binary, text has none but for
+	// some padding
+	binaryNULs = 0.01
+	// binaryControls is the share of control characters text doesn't use past which content is binary
+	binaryControls = 0.1
+)
+
+// IsBinary tells whether preview, the start of some content, looks binary rather than text: it
+// holds more than a few NUL bytes or too many control characters that text doesn't use. Content
+// starting with a BOM is text, and so are the bytes beyond ASCII, which the legacy encodings use
+func IsBinary(preview []byte) bool {
+	if len(preview) == 0 || hasBOM(preview) {
+		return false
+	}
+	var nuls, controls int
+	for _, c := range preview {
+		switch {
+		case c == 0:
+			nuls++
+			controls++
+		case c < 0x20 && !textControl(c), c == 0x7f:
+			controls++
+		}
+	}
+	n := float64(len(preview))
+	return float64(nuls) > binaryNULs*n || float64(controls) > binaryControls*n
+}
+
+// textControl tells whether the control character c is used by text: the white space, the
+// backspace of overstriking, the shifts and escapes of ISO-2022 and the end of file of DOS
+func textControl(c byte) bool {
+	switch c {
+	case '\b', '\t', '\n', '\v', '\f', '\r', 0x0e, 0x0f, 0x1a, 0x1b:
+		return true
+	}
+	return false
+}
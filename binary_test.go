@@ -0,0 +1,65 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// pngHeader is the start of a PNG image: its signature and the chunk of its size
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x01\x00\x00\x00\x01\x00\x08\x06\x00\x00\x00\x5c\x72\xa8\x66"
+
+func TestIsBinary(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected bool
+	}{
+		{"", false},
+		{"plain ascii\r\n\tindented\n", false},
+		{"caf\xe9 \xf1and\xfa", false},
+		{"\x1b[1mbold\x1b[0m", false},
+		{"text padded with a NUL" + strings.Repeat(" ", 100) + "\x00", false},
+		{string(utf16lebom) + "a\x00b\x00", false},
+		{pngHeader, true},
+		{"\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00", true},
+		{"abc\x00", true},
+		{"\x01\x02\x03\x04text without NULs", true},
+	}
+
+	for i, tt := range tests {
+		if got := IsBinary([]byte(tt.feed)); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %v - expected: %v", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestRejectBinary(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+		err      error
+	}{
+		{"caf\xe9", nil, "café", nil},
+		{pngHeader + string(bytes.Repeat([]byte{0}, 100)), nil, "", ErrBinaryFile},
+		{"a\x00b\x00", []Option{WithContentType("text/plain; charset=utf-16le")}, "ab", nil},
+		{"a\x00b\x00", nil, "", ErrBinaryFile},
+	}
+
+	for i, tt := range tests {
+		var got []byte
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), append(tt.opts, WithRejectBinary())...)
+		if err == nil {
+			got, err = ioutil.ReadAll(r)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. feeded: %q -> got error: %v - expected: %v", i, tt.feed, err, tt.err)
+			continue
+		}
+		if tt.err == nil && string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package txtopener
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Charmap is a single-byte encoding built at runtime, usually from a Unicode mapping table.
+// A byte may map to a sequence of runes; bytes without a mapping decode to U+FFFD
+type Charmap struct {
+	name   string
+	decode [256]string
+	encode map[rune]byte
+	seqs   map[string]byte
+	maxSeq int
+}
+
+var _ encoding.Encoding = (*Charmap)(nil)
+
+// NewCharmap builds a Charmap named name from a byte to text table.
+// Bytes missing from table are undefined in the resulting encoding
+func NewCharmap(name string, table map[byte]string) *Charmap {
+	c := &Charmap{name: name, encode: make(map[rune]byte), seqs: make(map[string]byte)}
+	for b := 0; b < 256; b++ {
+		s, ok := table[byte(b)]
+		if !ok || s == "" {
+			continue
+		}
+		c.decode[b] = s
+		if utf8.RuneCountInString(s) == 1 {
+			r, _ := utf8.DecodeRuneInString(s)
+			if _, dup := c.encode[r]; !dup {
+				c.encode[r] = byte(b)
+			}
+			continue
+		}
+		if _, dup := c.seqs[s]; !dup {
+			c.seqs[s] = byte(b)
+		}
+		if len(s) > c.maxSeq {
+			c.maxSeq = len(s)
+		}
+	}
+	return c
+}
+
+// LoadCharmap reads a mapping table in the format used by the files published on unicode.org
+// (MAPPINGS/VENDORS) and returns the corresponding single-byte encoding.
+// Every non comment line holds a byte code and, optionally, the code point(s) it maps to:
+//
+//	0x80	0x20AC	#EURO SIGN
+//	0x81		#UNDEFINED
+//	0xA1	0x0E01+0x0E4D	#sequence of code points
+func LoadCharmap(r io.Reader, name string) (*Charmap, error) {
+	table := make(map[byte]string)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		code, err := parseCode(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("txtopener: line %d: %v", line, err)
+		}
+		if code > 0xff {
+			return nil, fmt.Errorf("txtopener: line %d: code %s is not a single byte", line, fields[0])
+		}
+		if len(fields) == 1 {
+			continue
+		}
+
+		var sb strings.Builder
+		for _, f := range strings.Split(fields[1], "+") {
+			cp, err := parseCode(f)
+			if err != nil {
+				return nil, fmt.Errorf("txtopener: line %d: %v", line, err)
+			}
+			if !utf8.ValidRune(rune(cp)) {
+				return nil, fmt.Errorf("txtopener: line %d: invalid code point %s", line, f)
+			}
+			sb.WriteRune(rune(cp))
+		}
+		table[byte(code)] = sb.String()
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return NewCharmap(name, table), nil
+}
+
+// parseCode parses a hexadecimal value written as 0xNNNN
+func parseCode(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return 0, fmt.Errorf("malformed code %q", s)
+	}
+	v, err := strconv.ParseUint(s[2:], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed code %q", s)
+	}
+	return v, nil
+}
+
+// String returns the name of the charmap
+func (c *Charmap) String() string {
+	return c.name
+}
+
+// DecodeByte returns the first rune byte b decodes to, or U+FFFD if b is undefined
+func (c *Charmap) DecodeByte(b byte) rune {
+	if c.decode[b] == "" {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRuneInString(c.decode[b])
+	return r
+}
+
+// NewDecoder implements encoding.Encoding
+func (c *Charmap) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: charmapDecoder{c: c}}
+}
+
+// NewEncoder implements encoding.Encoding
+func (c *Charmap) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: charmapEncoder{c: c}}
+}
+
+type charmapDecoder struct {
+	c *Charmap
+	transform.NopResetter
+}
+
+func (d charmapDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for _, b := range src {
+		s := d.c.decode[b]
+		if s == "" {
+			s = "\uFFFD"
+		}
+		if nDst+len(s) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], s)
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
+
+type charmapEncoder struct {
+	c *Charmap
+	transform.NopResetter
+}
+
+func (e charmapEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+
+		if n, b, short := e.c.matchSeq(src[nSrc:], atEOF); short {
+			return nDst, nSrc, transform.ErrShortSrc
+		} else if n > 0 {
+			dst[nDst] = b
+			nDst++
+			nSrc += n
+			continue
+		}
+
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			return nDst, nSrc, repertoireError(0x1a)
+		}
+		b, ok := e.c.encode[r]
+		if !ok {
+			return nDst, nSrc, repertoireError(0x1a)
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+// matchSeq looks for the longest multi-rune sequence of the charmap at the start of src.
+// short reports that src ends before a sequence could be told apart
+func (c *Charmap) matchSeq(src []byte, atEOF bool) (n int, b byte, short bool) {
+	if c.maxSeq == 0 {
+		return 0, 0, false
+	}
+	if len(src) > c.maxSeq {
+		src = src[:c.maxSeq]
+	}
+	head := string(src)
+	for s, v := range c.seqs {
+		if strings.HasPrefix(head, s) && len(s) > n {
+			n, b = len(s), v
+		} else if !atEOF && len(head) < len(s) && strings.HasPrefix(s, head) {
+			short = true
+		}
+	}
+	return n, b, short
+}
+
+// repertoireError is returned by the encoders of this package when a rune can't be represented.
+// It satisfies the interface checked by encoding.ReplaceUnsupported and encoding.HTMLEscapeUnsupported
+type repertoireError byte
+
+func (r repertoireError) Error() string {
+	return "txtopener: rune not supported by encoding"
+}
+
+func (r repertoireError) Replacement() byte {
+	return byte(r)
+}
@@ -0,0 +1,61 @@
+package txtopener
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMapping = `#
+#	Name:     test to Unicode table
+#
+0x41	0x0041	#LATIN CAPITAL LETTER A
+0x62	0x0062	#LATIN SMALL LETTER B
+0x80	0x20AC	#EURO SIGN
+0x81		#UNDEFINED
+0xA1	0x0041+0x0301	#A WITH COMBINING ACUTE
+`
+
+func TestLoadCharmap(t *testing.T) {
+	cm, err := LoadCharmap(strings.NewReader(testMapping), "test")
+	if err != nil {
+		t.Fatalf("error en LoadCharmap: %v", err)
+	}
+
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{"", ""},
+		{"Ab", "Ab"},
+		{"\x80b", "€b"},
+		{"\x81", "�"},
+		{"\xa1", "Á"},
+	}
+
+	for i, tt := range tests {
+		got, err := cm.NewDecoder().String(tt.feed)
+		if err != nil {
+			t.Errorf("%d. decode error: %v", i, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		if strings.ContainsRune(tt.expected, '�') {
+			continue
+		}
+		back, err := cm.NewEncoder().String(got)
+		if err != nil {
+			t.Errorf("%d. encode error: %v", i, err)
+		}
+		if back != tt.feed {
+			t.Errorf("%d. round trip: %q -> got: %q - expected: %q", i, got, back, tt.feed)
+		}
+	}
+
+	if _, err := cm.NewEncoder().String("z"); err == nil {
+		t.Errorf("expected an error encoding an unmapped rune")
+	}
+	if _, err := LoadCharmap(strings.NewReader("0x100\t0x0041\n"), "bad"); err == nil {
+		t.Errorf("expected an error for a multi-byte code")
+	}
+}
@@ -0,0 +1,94 @@
+package txtopener
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+)
+
+// chunkRounds is the number of randomized readings CheckChunking compares with the one-shot one
+const chunkRounds = 32
+
+// maxChunk is the largest read CheckChunking makes, small enough to split every multi-byte
+// sequence, BOM and Base64 quantum somewhere
+const maxChunk = 7
+
+// CheckChunking is a debug helper that reads data through NewReaderOpts with opts many times,
+// feeding the reader with tiny reads of random sizes and reading its output into buffers of
+// random sizes, and fails if any of the results differs from reading data in one go.
+// The one-shot reading is of a bytes.Reader, which the reader seeks back to read the preview
+// again, while the chunked ones can't seek and have the preview buffered, so the check compares
+// those two paths as well. Mismatches point to transformers, custom ones included, that lose or
+// change sequences split across the boundary of the preview, which only inputs longer than the
+// preview size have, or of the underlying reads. seed makes the failures reproducible
+func CheckChunking(data []byte, seed int64, opts ...Option) error {
+	r, err := NewReaderOpts(bytes.NewReader(data), opts...)
+	if err != nil {
+		return err
+	}
+	expected, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+	for round := 0; round < chunkRounds; round++ {
+		got, err := readChunked(data, rnd, opts)
+		if err != nil {
+			return fmt.Errorf("txtopener: round %d: %v", round, err)
+		}
+		if !bytes.Equal(got, expected) {
+			m := len(got)
+			if len(expected) < m {
+				m = len(expected)
+			}
+			i := mismatch(got[:m], expected[:m])
+			if i < 0 {
+				i = m
+			}
+			return fmt.Errorf("txtopener: round %d: output differs from the one-shot decoding at byte %d", round, i)
+		}
+	}
+	return nil
+}
+
+// readChunked is a reading of data with reads, in and out, of random sizes
+func readChunked(data []byte, rnd *rand.Rand, opts []Option) ([]byte, error) {
+	r, err := NewReaderOpts(&chunkedReader{data: data, rnd: rnd}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	buf := make([]byte, maxChunk)
+	for {
+		n, err := r.Read(buf[:1+rnd.Intn(maxChunk)])
+		out = append(out, buf[:n]...)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+}
+
+// chunkedReader returns data in reads of random sizes up to maxChunk, empty ones included
+type chunkedReader struct {
+	data []byte
+	rnd  *rand.Rand
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if len(c.data) == 0 {
+		return 0, io.EOF
+	}
+	n := c.rnd.Intn(maxChunk + 1)
+	if n > len(p) {
+		n = len(p)
+	}
+	n = copy(p[:n], c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
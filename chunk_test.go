@@ -0,0 +1,69 @@
+package txtopener
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+func TestCheckChunking(t *testing.T) {
+	var tests = []struct {
+		data string
+		opts []Option
+	}{
+		{"\xef\xbb\xbfaño, 日本語 y más", nil},
+		{"\xff\xfea\x00\xf1\x00o\x00", nil},
+		{string(bytes.Repeat([]byte("ñandú "), 2000)), nil},
+		{"<meta charset=\"shift_jis\">\x83e\x83X\x83g\x83e\x83X\x83g", nil},
+		{"bWFuw7FhbmEgZXMgb3RybyBkw61h", []Option{WithBase64()}},
+	}
+
+	for i, tt := range tests {
+		if err := CheckChunking([]byte(tt.data), int64(i), tt.opts...); err != nil {
+			t.Errorf("%d. feeded: %q -> got: %v", i, tt.data, err)
+		}
+	}
+
+	// a decoder that, instead of waiting for the rest of a sequence split across two calls,
+	// replaces its first byte
+	broken := DetectorFunc(func(preview []byte) (encoding.Encoding, string, float64) {
+		return brokenEncoding{}, "broken", 1
+	})
+	if err := CheckChunking(bytes.Repeat([]byte("\x81a\x81b "), 3000), 1, WithDetector(broken)); err == nil {
+		t.Errorf("expected the split sequences to be caught")
+	}
+}
+
+// brokenEncoding decodes \x81 and the byte after it as one rune
+type brokenEncoding struct{}
+
+func (brokenEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: brokenDecoder{}}
+}
+
+func (brokenEncoding) NewEncoder() *encoding.Encoder {
+	return encoding.Nop.NewEncoder()
+}
+
+type brokenDecoder struct{ transform.NopResetter }
+
+func (brokenDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := rune(src[nSrc]), 1
+		if r == 0x81 {
+			r = '?'
+			if nSrc+1 < len(src) {
+				r, size = 0x100+rune(src[nSrc+1]), 2
+			}
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,44 @@
+package txtopener
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// decodeClipboard decodes a clipboard payload of text in the encoding e, as placed by Windows:
+// the text ends at the first NUL character, of two bytes if wide, and its lines end with CRLF,
+// which become LF
+func decodeClipboard(b []byte, e encoding.Encoding, wide bool) (string, error) {
+	b = b[:clipboardEnd(b, wide)]
+	s, err := e.NewDecoder().Bytes(b)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.ReplaceAll(s, []byte("\r\n"), []byte("\n"))), nil
+}
+
+// encodeClipboard is the reverse of decodeClipboard: it encodes s with CRLF line endings and
+// the terminating NUL character
+func encodeClipboard(s string, e encoding.Encoding) ([]byte, error) {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\n", "\r\n") + "\x00"
+	return e.NewEncoder().Bytes([]byte(s))
+}
+
+// clipboardEnd returns the position of the terminating NUL of b, len(b) if there is none
+func clipboardEnd(b []byte, wide bool) int {
+	if !wide {
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			return i
+		}
+		return len(b)
+	}
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0 && b[i+1] == 0 {
+			return i
+		}
+	}
+	return len(b) &^ 1
+}
@@ -0,0 +1,50 @@
+package txtopener
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestClipboard(t *testing.T) {
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	var tests = []struct {
+		payload  string
+		wide     bool
+		text     string
+		expected string
+	}{
+		{"caf\xe9\r\nna\xefve\x00garbage", false, "café\nnaïve", "caf\xe9\r\nna\xefve\x00"},
+		{"sin fin", false, "sin fin", "sin fin\x00"},
+		{"a\x00\r\x00\n\x00\xfc\x00\x00\x00x\x00", true, "a\nü", "a\x00\r\x00\n\x00\xfc\x00\x00\x00"},
+		{"\x00\x01\x00\x00", true, "Ā", "\x00\x01\x00\x00"},
+	}
+
+	for i, tt := range tests {
+		var e encoding.Encoding = charmap.Windows1252
+		if tt.wide {
+			e = utf16le
+		}
+		got, err := decodeClipboard([]byte(tt.payload), e, tt.wide)
+		if err != nil || got != tt.text {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q", i, tt.payload, got, err, tt.text)
+		}
+
+		enc, err := encodeClipboard(tt.text, e)
+		if err != nil || string(enc) != tt.expected {
+			t.Errorf("%d. encoded: %q -> got: %q, %v - expected: %q", i, tt.text, enc, err, tt.expected)
+		}
+	}
+
+	if got := codePageLabel(1251); got != "windows-1251" {
+		t.Errorf("codePageLabel(1251) -> got: %q", got)
+	}
+	if got := codePageLabel(850); got != "ibm850" {
+		t.Errorf("codePageLabel(850) -> got: %q", got)
+	}
+	if got := codePageLabel(12345); got != "" {
+		t.Errorf("codePageLabel(12345) -> got: %q", got)
+	}
+}
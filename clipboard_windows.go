@@ -0,0 +1,44 @@
+package txtopener
+
+import (
+	"syscall"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var procGetACP = syscall.NewLazyDLL("kernel32.dll").NewProc("GetACP")
+
+// DecodeCFText decodes a CF_TEXT clipboard payload, text in the ANSI code page of the system,
+// to UTF-8. The text ends at the first NUL and its CRLF line endings become LF
+func DecodeCFText(b []byte) (string, error) {
+	return decodeClipboard(b, ansiCodePage(), false)
+}
+
+// EncodeCFText encodes s as a CF_TEXT clipboard payload: in the ANSI code page of the system,
+// with CRLF line endings and NUL terminated
+func EncodeCFText(s string) ([]byte, error) {
+	return encodeClipboard(s, ansiCodePage())
+}
+
+// DecodeCFUnicodeText decodes a CF_UNICODETEXT clipboard payload, NUL terminated UTF-16LE text,
+// to UTF-8 with LF line endings
+func DecodeCFUnicodeText(b []byte) (string, error) {
+	return decodeClipboard(b, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), true)
+}
+
+// EncodeCFUnicodeText encodes s as a CF_UNICODETEXT clipboard payload: in UTF-16LE, with CRLF
+// line endings and NUL terminated
+func EncodeCFUnicodeText(s string) ([]byte, error) {
+	return encodeClipboard(s, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM))
+}
+
+// ansiCodePage returns the encoding of the ANSI code page of the system, windows-1252 if unknown
+func ansiCodePage() encoding.Encoding {
+	cp, _, _ := procGetACP.Call()
+	if e, _ := lookupLabel(codePageLabel(uint32(cp))); e != nil {
+		return e
+	}
+	return charmap.Windows1252
+}
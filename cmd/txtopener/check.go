@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leo2904/txtopener"
+)
+
+// check implements "txtopener check [-staged] [-bom] [-allow encoding] [-preview n] [paths...]",
+// which reports the text files that aren't valid UTF-8, start with a BOM or are written in an
+// encoding not allowed.
+// With -staged it checks the content staged in the Git index instead, as a pre-commit hook needs:
+//
+//	#!/bin/sh
+//	exec txtopener check -staged
+//
+// The files that look binary, as txtopener.IsBinary tells, are ignored. The exit status is 0
+// when every file passes, 1 when some doesn't and 2 on errors
+func check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: txtopener check [-staged] [-bom] [-allow encoding] [-preview n] [paths...]")
+		flags.PrintDefaults()
+	}
+	staged := flags.Bool("staged", false, "check the files staged in the Git index")
+	bom := flags.Bool("bom", false, "accept files starting with a byte order mark")
+	preview := flags.Int("preview", 0, "examine the first `n` bytes of every file to detect its encoding, 10240 if 0")
+	var allow stringList
+	flags.Var(&allow, "allow", "accept files in `encoding` besides UTF-8 (repeatable)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *preview > 0 {
+		txtopener.SetDefaultPreviewSize(*preview)
+	}
+	if !*staged && flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	allowed := map[string]bool{}
+	for _, label := range allow {
+		name, ok := txtopener.CanonicalLabel(label)
+		if !ok {
+			errorf("unknown encoding %q", label)
+			return 2
+		}
+		allowed[name] = true
+	}
+
+	paths, read := flags.Args(), os.ReadFile
+	if *staged {
+		var err error
+		if paths, err = stagedFiles(); err != nil {
+			errorf("%v", err)
+			return 2
+		}
+		read = stagedContent
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	status := 0
+	for _, path := range paths {
+		data, err := read(path)
+		if err != nil {
+			out.Flush()
+			errorf("%v", err)
+			status = 2
+			continue
+		}
+		if problem := checkText(data, *bom, allowed); problem != "" {
+			fmt.Fprintf(out, "%s: %s\n", path, problem)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+	return status
+}
+
+// checkText returns what is wrong with data, nothing if it passes the check
+func checkText(data []byte, bom bool, allowed map[string]bool) string {
+	preview := data
+	if n := txtopener.DefaultPreviewSize(); len(preview) > n {
+		preview = preview[:n]
+	}
+	if txtopener.IsBinary(preview) {
+		return ""
+	}
+	_, name, certain := txtopener.DetectEncoding(data, "")
+	hasBOM := certain && hasByteOrderMark(data)
+	switch {
+	case hasBOM && !bom:
+		return "starts with a byte order mark"
+	case hasBOM && name == "utf-8" || !hasBOM && utf8.Valid(data):
+		if !utf8.Valid(data) {
+			return "not valid UTF-8"
+		}
+		return ""
+	// the last resort of the detection, whose labels WHATWG gives to windows-1252
+	case allowed[name] || name == "ISO 8859-1" && allowed["windows-1252"]:
+		return ""
+	default:
+		return fmt.Sprintf("not valid UTF-8, looks like %s", name)
+	}
+}
+
+// hasByteOrderMark tells whether data starts with the BOM of UTF-8, UTF-16 or UTF-32. That of
+// UTF-32LE starts with the one of UTF-16LE
+func hasByteOrderMark(data []byte) bool {
+	return bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}) ||
+		bytes.HasPrefix(data, []byte{0xfe, 0xff}) || bytes.HasPrefix(data, []byte{0xff, 0xfe}) ||
+		bytes.HasPrefix(data, []byte{0x00, 0x00, 0xfe, 0xff})
+}
+
+// stagedFiles returns the paths, relative to the top of the working tree, of the files added,
+// copied, modified or renamed in the Git index
+func stagedFiles() ([]string, error) {
+	out, err := git("diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// stagedContent returns the content of the file path in the Git index
+func stagedContent(path string) ([]byte, error) {
+	return git("show", ":"+path)
+}
+
+// git runs the git command with args and returns its output
+func git(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %v", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckText(t *testing.T) {
+	var tests = []struct {
+		data     string
+		allowed  map[string]bool
+		expected string
+	}{
+		{"café", nil, ""},
+		{"caf\xe9", nil, "not valid UTF-8, looks like ISO 8859-1"},
+		{"caf\xe9", map[string]bool{"windows-1252": true}, ""},
+		{"\x00\x01\x02\x03\xe9", nil, ""},
+		{strings.Repeat("caf\xe9 ", 20) + "\x00", nil, "not valid UTF-8, looks like ISO 8859-1"},
+		{"\xef\xbb\xbfcafé", nil, "starts with a byte order mark"},
+	}
+
+	for i, tt := range tests {
+		if got := checkText([]byte(tt.data), false, tt.allowed); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.data, got, tt.expected)
+		}
+	}
+}
+
+func TestCheckAllow(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "latin1.txt")
+	if err := os.WriteFile(name, []byte("caf\xe9"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		allow  string
+		status int
+	}{
+		{"latin-1", 0},
+		{"CP-1252", 0},
+		{"koi8-r", 1},
+		{"nope", 2},
+	}
+
+	for i, tt := range tests {
+		if status, _ := run(t, check, "", "-allow", tt.allow, name); status != tt.status {
+			t.Errorf("%d. -allow %s -> got: %d - expected: %d", i, tt.allow, status, tt.status)
+		}
+	}
+}
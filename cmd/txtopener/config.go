@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/leo2904/txtopener"
+)
+
+// configFile is the name of the file, in the current directory, the profiles are read from
+// unless -config names another one
+const configFile = "txtopener.yaml"
+
+// config is the content of a configuration file:
+//
+//	profiles:
+//	  repo:
+//	    from: windows-1252
+//	    encoding: utf-8
+//	    bom: false
+//	    newline: lf
+//	    exclude: [".git", "*.png"]
+//	    workers: 4
+//	    out-dir: converted
+//	    editorconfig: true
+//	    gitattributes: skip
+//	    manifest:
+//	      legacy/readme.txt: koi8-r
+type config struct {
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+// profile is a named conversion policy
+type profile struct {
+	From     string   `yaml:"from"`
+	Encoding string   `yaml:"encoding"`
+	BOM      bool     `yaml:"bom"`
+	Newline  string   `yaml:"newline"`
+	Exclude  []string `yaml:"exclude"`
+	Workers  int      `yaml:"workers"`
+	OutDir   string   `yaml:"out-dir"`
+
+	EditorConfig  bool   `yaml:"editorconfig"`
+	GitAttributes string `yaml:"gitattributes"`
+
+	Manifest map[string]string `yaml:"manifest"`
+}
+
+// newlines maps the names of the line endings accepted by profiles and flags to the endings.
+// keep, like an empty name, leaves them as they are
+var newlines = map[string]string{
+	"":     "",
+	"keep": "",
+	"lf":   "\n",
+	"crlf": "\r\n",
+	"cr":   "\r",
+}
+
+// gitPolicies maps the names of the policies for the working-tree-encoding attributes to them
+var gitPolicies = map[string]txtopener.GitAttributesPolicy{
+	"":       txtopener.GitAttributesIgnore,
+	"ignore": txtopener.GitAttributesIgnore,
+	"skip":   txtopener.GitAttributesSkip,
+	"follow": txtopener.GitAttributesFollow,
+}
+
+// loadConfig reads the configuration file name. A missing default file is an empty configuration
+func loadConfig(name string) (*config, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) && name == configFile {
+		return &config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c config
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return &c, nil
+}
+
+// loadManifest reads a manifest file, a YAML mapping of paths to the encodings they are written in
+func loadManifest(name string) (map[string]string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]string
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return m, nil
+}
+
+// options returns the conversion options of the profile
+func (p profile) options() (txtopener.ConvertOptions, error) {
+	newline, ok := newlines[p.Newline]
+	if !ok {
+		return txtopener.ConvertOptions{}, fmt.Errorf("unknown newline %q, use lf, crlf, cr or keep", p.Newline)
+	}
+	git, ok := gitPolicies[p.GitAttributes]
+	if !ok {
+		return txtopener.ConvertOptions{}, fmt.Errorf("unknown gitattributes policy %q, use skip, follow or ignore", p.GitAttributes)
+	}
+	return txtopener.ConvertOptions{
+		From:          p.From,
+		Encoding:      p.Encoding,
+		BOM:           p.BOM,
+		Newline:       newline,
+		Exclude:       p.Exclude,
+		Workers:       p.Workers,
+		OutDir:        p.OutDir,
+		EditorConfig:  p.EditorConfig,
+		GitAttributes: git,
+		Manifest:      p.Manifest,
+	}, nil
+}
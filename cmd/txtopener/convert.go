@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leo2904/txtopener"
+)
+
+// convert implements "txtopener convert [flags] <paths...>", which rewrites the given files and
+// directory trees in the target encoding, in place or into the directory given with -out-dir.
+// The settings come from the profile chosen with -profile, if any, and the flags given override them.
+// With a profile and without paths the current directory is converted.
+// Without -from the encoding of every file is detected.
+// With any of the flags of iconv, -f and -t or --from-code and --to-code, and -o, it works like
+// iconv instead: the files, or the standard input if none, are converted to the standard output
+// or to the file given with -o, and left as they are. The settings that only apply to trees,
+// -exclude, -workers, -out-dir, -editorconfig, -gitattributes and -manifest, conflict with them
+func convert(args []string) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: txtopener convert [-profile name] [-config file] [-from encoding] [-to encoding] [-bom] [-newline lf|crlf|cr|keep] [-exclude pattern] [-workers n] [-out-dir dir] [-editorconfig] [-gitattributes skip|follow|ignore] [-manifest file] <paths...>")
+		fmt.Fprintln(flags.Output(), "       txtopener convert -profile name [-config file] [flags] [paths...]")
+		fmt.Fprintln(flags.Output(), "       txtopener convert [-f encoding] [-t encoding] [-o file] [-bom] [-newline lf|crlf|cr|keep] [files...]")
+		flags.PrintDefaults()
+	}
+	profileName := flags.String("profile", "", "use the named profile of the configuration file")
+	configName := flags.String("config", configFile, "read the profiles from `file`")
+	var p profile
+	flags.StringVar(&p.From, "from", "", "convert from `encoding` instead of detecting it")
+	flags.StringVar(&p.From, "f", "", "like iconv, convert to the standard output from `encoding`")
+	flags.StringVar(&p.From, "from-code", "", "same as -f `encoding`")
+	flags.StringVar(&p.Encoding, "to", "utf-8", "convert to `encoding`")
+	flags.StringVar(&p.Encoding, "t", "utf-8", "like iconv, convert to the standard output to `encoding`")
+	flags.StringVar(&p.Encoding, "to-code", "utf-8", "same as -t `encoding`")
+	output := flags.String("o", "", "like iconv, write the output to `file` instead of the standard output")
+	flags.BoolVar(&p.BOM, "bom", false, "start the files with a byte order mark")
+	flags.StringVar(&p.Newline, "newline", "keep", "replace the line endings with lf, crlf or cr")
+	var exclude stringList
+	flags.Var(&exclude, "exclude", "leave alone the files and directories matching `pattern` (repeatable)")
+	flags.IntVar(&p.Workers, "workers", 1, "convert `n` files at the same time")
+	flags.StringVar(&p.OutDir, "out-dir", "", "write the converted files into `dir`, mirroring the trees, instead of in place")
+	flags.BoolVar(&p.EditorConfig, "editorconfig", false, "convert to the charset of the .editorconfig files where they set one")
+	flags.StringVar(&p.GitAttributes, "gitattributes", "ignore", "skip or follow the working-tree-encoding of the .gitattributes files")
+	manifestName := flags.String("manifest", "", "read from `file` the encodings of the files the detection gets wrong")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	var iconv bool
+	var treeFlags []string
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "f", "from-code", "t", "to-code", "o":
+			iconv = true
+		case "exclude", "workers", "out-dir", "editorconfig", "gitattributes", "manifest":
+			treeFlags = append(treeFlags, "-"+f.Name)
+		}
+	})
+	if !iconv && flags.NArg() == 0 && *profileName == "" {
+		flags.Usage()
+		return 2
+	}
+
+	if *profileName != "" {
+		c, err := loadConfig(*configName)
+		if err != nil {
+			errorf("%v", err)
+			return 2
+		}
+		base, ok := c.Profiles[*profileName]
+		if !ok {
+			errorf("no profile %q in %s", *profileName, *configName)
+			return 2
+		}
+		// the flags given explicitly win over the profile
+		flags.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "from", "f", "from-code":
+				base.From = p.From
+			case "to", "t", "to-code":
+				base.Encoding = p.Encoding
+			case "bom":
+				base.BOM = p.BOM
+			case "newline":
+				base.Newline = p.Newline
+			case "exclude":
+				base.Exclude = append(base.Exclude, exclude...)
+			case "workers":
+				base.Workers = p.Workers
+			case "out-dir":
+				base.OutDir = p.OutDir
+			case "editorconfig":
+				base.EditorConfig = p.EditorConfig
+			case "gitattributes":
+				base.GitAttributes = p.GitAttributes
+			}
+		})
+		p = base
+	} else {
+		p.Exclude = exclude
+	}
+
+	if *manifestName != "" {
+		m, err := loadManifest(*manifestName)
+		if err != nil {
+			errorf("%v", err)
+			return 2
+		}
+		// the entries of the file win over those of the profile
+		if p.Manifest == nil {
+			p.Manifest = map[string]string{}
+		}
+		for path, label := range m {
+			p.Manifest[path] = label
+		}
+	}
+
+	opts, err := p.options()
+	if err != nil {
+		errorf("%v", err)
+		return 2
+	}
+
+	if iconv {
+		if tree := p.treeSettings(treeFlags); len(tree) > 0 {
+			errorf("%s conflict with -f, -t and -o", strings.Join(tree, ", "))
+			return 2
+		}
+		if err := convertStream(flags.Args(), *output, opts); err != nil {
+			errorf("%v", err)
+			return 1
+		}
+		return 0
+	}
+
+	roots := flags.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	status := 0
+	for _, root := range roots {
+		if err := convertPath(root, opts); err != nil {
+			reportErrors(err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// treeSettings returns the flags among given, and the settings of p, that only apply to trees
+func (p profile) treeSettings(given []string) []string {
+	set := given
+	seen := make(map[string]bool)
+	for _, name := range given {
+		seen[name] = true
+	}
+	for _, s := range []struct {
+		name string
+		on   bool
+	}{
+		{"exclude", len(p.Exclude) > 0},
+		{"workers", p.Workers > 1},
+		{"out-dir", p.OutDir != ""},
+		{"editorconfig", p.EditorConfig},
+		{"gitattributes", p.GitAttributes != "" && p.GitAttributes != "ignore"},
+		{"manifest", len(p.Manifest) > 0},
+	} {
+		if s.on && !seen["-"+s.name] {
+			set = append(set, "-"+s.name)
+		}
+	}
+	return set
+}
+
+// convertPath converts the file or directory tree root
+func convertPath(root string, opts txtopener.ConvertOptions) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return txtopener.ConvertTree(root, opts)
+	}
+	return txtopener.ConvertFile(root, opts)
+}
+
+// convertStream converts the files names, or the standard input if none, one after the other
+// to the file output, or to the standard output if empty
+func convertStream(names []string, output string, opts txtopener.ConvertOptions) (err error) {
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	defer func() {
+		if ferr := bw.Flush(); err == nil {
+			err = ferr
+		}
+	}()
+
+	if len(names) == 0 {
+		return txtopener.ConvertStream(bw, os.Stdin, opts)
+	}
+	for _, name := range names {
+		if err := convertStreamFile(bw, name, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertStreamFile converts the file name, "-" for the standard input, to w
+func convertStreamFile(w io.Writer, name string, opts txtopener.ConvertOptions) error {
+	if name == "-" {
+		return txtopener.ConvertStream(w, os.Stdin, opts)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := txtopener.ConvertStream(w, f, opts); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// stringList is a flag.Value collecting every value given to a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// run calls the command cmd with args reading stdin from a file with the content in, and
+// returns its exit status and what it wrote to stdout
+func run(t *testing.T, cmd func([]string) int, in string, args ...string) (int, string) {
+	t.Helper()
+	dir := t.TempDir()
+	stdin, err := os.Create(filepath.Join(dir, "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := stdin.WriteString(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	oldIn, oldOut := os.Stdin, os.Stdout
+	os.Stdin, os.Stdout = stdin, stdout
+	status := cmd(args)
+	os.Stdin, os.Stdout = oldIn, oldOut
+
+	out, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, string(out)
+}
+
+func TestConvertIconv(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	out := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(in, []byte("caf\xe9\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		stdin    string
+		args     []string
+		expected string
+	}{
+		// iconv -f X -t Y in > out
+		{"", []string{"-f", "windows-1252", "-t", "utf-8", in}, "café\n"},
+		// cat in | iconv -f X -t Y
+		{"caf\xe9\n", []string{"-f", "windows-1252", "-t", "utf-8"}, "café\n"},
+		{"café\n", []string{"--from-code=utf-8", "--to-code=windows-1252"}, "caf\xe9\n"},
+		{"uno\n", []string{"-t", "utf-16le", "-", in}, "u\x00n\x00o\x00\n\x00c\x00a\x00f\x00\xe9\x00\n\x00"},
+	}
+
+	for i, tt := range tests {
+		status, got := run(t, convert, tt.stdin, tt.args...)
+		if status != 0 || got != tt.expected {
+			t.Errorf("%d. %q -> got: %d, %q - expected: 0, %q", i, tt.args, status, got, tt.expected)
+		}
+	}
+
+	if status, got := run(t, convert, "", "-f", "windows-1252", "-t", "utf-8", "-o", out, in); status != 0 || got != "" {
+		t.Errorf("-o -> got: %d, %q - expected: 0, \"\"", status, got)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "café\n" {
+		t.Errorf("-o wrote %q - expected: %q", got, "café\n")
+	}
+	if got, _ := os.ReadFile(in); string(got) != "caf\xe9\n" {
+		t.Errorf("the input was rewritten: %q", got)
+	}
+
+	if status, _ := run(t, convert, "", "-from", "windows-1252", in); status != 0 {
+		t.Errorf("-from -> got status %d", status)
+	}
+	if got, _ := os.ReadFile(in); string(got) != "café\n" {
+		t.Errorf("-from didn't convert in place: %q", got)
+	}
+}
+
+func TestConvertConflicts(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.txt")
+	config := filepath.Join(dir, "txtopener.yaml")
+	if err := os.WriteFile(in, []byte("caf\xe9\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config, []byte("profiles:\n  repo:\n    out-dir: converted\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = [][]string{
+		// no paths nor flags of iconv is a usage error, not a read of the standard input
+		nil,
+		{"-to", "utf-16le"},
+		{"-t", "utf-8", "-exclude", "*.png", in},
+		{"-t", "utf-8", "-workers", "4", in},
+		{"-o", filepath.Join(dir, "out.txt"), "-out-dir", filepath.Join(dir, "out"), in},
+		{"-f", "windows-1252", "-editorconfig", in},
+		{"-t", "utf-8", "-gitattributes", "skip", in},
+		{"-t", "utf-8", "-manifest", config, in},
+		{"-config", config, "-profile", "repo", "-t", "utf-8", in},
+	}
+
+	for i, args := range tests {
+		status, got := run(t, convert, "caf\xe9\n", args...)
+		if status != 2 || got != "" {
+			t.Errorf("%d. %q -> got: %d, %q - expected: 2, \"\"", i, args, status, got)
+		}
+	}
+	if got, _ := os.ReadFile(in); string(got) != "caf\xe9\n" {
+		t.Errorf("the input was rewritten: %q", got)
+	}
+}
+
+func TestConvertProfile(t *testing.T) {
+	dir := t.TempDir()
+	config := "profiles:\n  repo:\n    from: windows-1252\n    newline: lf\n    exclude: [\"vendor\", \"*.dat\"]\n    workers: 2\n"
+	files := map[string]string{
+		"txtopener.yaml": config,
+		"a.txt":          "caf\xe9\r\n",
+		"src/b.txt":      "ni\xf1o\r\n",
+		"vendor/c.txt":   "caf\xe9\r\n",
+		"data.dat":       "caf\xe9\r\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+
+	if status, _ := run(t, convert, "", "-profile", "repo"); status != 0 {
+		t.Fatalf("convert -profile repo -> got status %d", status)
+	}
+
+	expected := map[string]string{
+		"txtopener.yaml": config,
+		"a.txt":          "café\n",
+		"src/b.txt":      "niño\n",
+		"vendor/c.txt":   "caf\xe9\r\n",
+		"data.dat":       "caf\xe9\r\n",
+	}
+	for name, content := range expected {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("error en ReadFile: %v", err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s -> got: %q - expected: %q", name, got, content)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/leo2904/txtopener"
+)
+
+// uchardetNames maps the names of the encodings reported by uchardet differently
+var uchardetNames = map[string]string{
+	"ISO 8859-1":     "ISO-8859-1",
+	"iso-8859-8-i":   "ISO-8859-8",
+	"x-mac-cyrillic": "MAC-CYRILLIC",
+}
+
+// detect implements "txtopener detect [-format txtopener|uchardet] [-preview n] [paths...]", which
+// prints the encoding of every file, or of the standard input if none is given.
+// The uchardet format prints the names as uchardet does, in upper case with ASCII for the files
+// without any other character and prefixed with the path only if there are several files
+func detect(args []string) int {
+	flags := flag.NewFlagSet("detect", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: txtopener detect [-format txtopener|uchardet] [-preview n] [paths...]")
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "txtopener", "print the results in the `format` of txtopener or uchardet")
+	preview := flags.Int("preview", 0, "examine the first `n` bytes of every file to detect its encoding, 10240 if 0")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *preview > 0 {
+		txtopener.SetDefaultPreviewSize(*preview)
+	}
+	if *format != "txtopener" && *format != "uchardet" {
+		errorf("unknown format %q, use txtopener or uchardet", *format)
+		return 2
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	if flags.NArg() == 0 {
+		name, err := detectReader(os.Stdin, *format)
+		if err != nil {
+			errorf("%v", err)
+			return 1
+		}
+		fmt.Fprintln(out, name)
+		return 0
+	}
+
+	status := 0
+	for _, path := range flags.Args() {
+		name, err := detectFile(path, *format)
+		if err != nil {
+			out.Flush()
+			errorf("%v", err)
+			status = 1
+			continue
+		}
+		if *format == "uchardet" && flags.NArg() == 1 {
+			fmt.Fprintln(out, name)
+		} else {
+			fmt.Fprintf(out, "%s: %s\n", path, name)
+		}
+	}
+	return status
+}
+
+// detectFile returns the name of the encoding of the file path in format
+func detectFile(path, format string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return detectReader(f, format)
+}
+
+// detectReader returns the name of the encoding of the content of r in format
+func detectReader(r io.Reader, format string) (string, error) {
+	preview, err := io.ReadAll(io.LimitReader(r, int64(txtopener.DefaultPreviewSize())))
+	if err != nil {
+		return "", err
+	}
+	_, name, certain := txtopener.DetectEncoding(preview, "")
+	if format != "uchardet" {
+		return name, nil
+	}
+	if !certain && isASCII(preview) {
+		return "ASCII", nil
+	}
+	if n, ok := uchardetNames[name]; ok {
+		return n, nil
+	}
+	return strings.ToUpper(name), nil
+}
+
+// isASCII tells whether b holds only ASCII characters, escape sequences excluded
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 || c == 0x1b {
+			return false
+		}
+	}
+	return true
+}
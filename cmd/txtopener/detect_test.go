@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leo2904/txtopener"
+)
+
+func TestDetectPreview(t *testing.T) {
+	defer txtopener.SetDefaultPreviewSize(0)
+	late := strings.Repeat("a", 12000) + "caf\xc3\xa9"
+
+	var tests = []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "ISO 8859-1\n"},
+		{[]string{"-preview", "20000"}, "utf-8\n"},
+		{[]string{"-format", "uchardet"}, "ASCII\n"},
+		{[]string{"-format", "uchardet", "-preview", "20000"}, "UTF-8\n"},
+	}
+
+	for i, tt := range tests {
+		txtopener.SetDefaultPreviewSize(0)
+		if status, got := run(t, detect, late, tt.args...); status != 0 || got != tt.expected {
+			t.Errorf("%d. %q -> got: %d, %q - expected: 0, %q", i, tt.args, status, got, tt.expected)
+		}
+	}
+
+	txtopener.SetDefaultPreviewSize(20000)
+	if status, got := run(t, detect, late); status != 0 || got != "utf-8\n" {
+		t.Errorf("with SetDefaultPreviewSize -> got: %d, %q - expected: 0, %q", status, got, "utf-8\n")
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/leo2904/txtopener"
+)
+
+// grep implements "txtopener grep [-i] <pattern> <paths...>".
+// Every matching line is printed as path:line:offset:text where offset is the byte offset of the
+// first match in the original file. Like grep(1) the exit status is 0 when something matched,
+// 1 when nothing did and 2 on errors
+func grep(args []string) int {
+	flags := flag.NewFlagSet("grep", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: txtopener grep [-i] <pattern> <paths...>")
+		flags.PrintDefaults()
+	}
+	ignoreCase := flags.Bool("i", false, "ignore case distinctions")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() < 2 {
+		flags.Usage()
+		return 2
+	}
+
+	expr := flags.Arg(0)
+	if *ignoreCase {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		errorf("%v", err)
+		return 2
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	status := 1
+	for _, root := range flags.Args()[1:] {
+		found, err := grepPath(out, root, pattern)
+		if err != nil {
+			reportErrors(err)
+			status = 2
+		}
+		if found && status == 1 {
+			status = 0
+		}
+	}
+	return status
+}
+
+// grepPath searches the file or directory tree root
+func grepPath(out *bufio.Writer, root string, pattern *regexp.Regexp) (bool, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return false, err
+	}
+
+	found, last := false, ""
+	report := func(path string, m txtopener.Match) error {
+		found = true
+		key := fmt.Sprint(path, ":", m.Line)
+		if key == last {
+			return nil
+		}
+		last = key
+		_, err := fmt.Fprintf(out, "%s:%d:%d:%s\n", path, m.Line, m.Offset, m.Text)
+		return err
+	}
+
+	if info.IsDir() {
+		err = txtopener.Search(os.DirFS(root), pattern, func(m txtopener.Match) error {
+			return report(filepath.Join(root, filepath.FromSlash(m.Path)), m)
+		})
+		return found, err
+	}
+
+	f, err := os.Open(root)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	err = txtopener.Grep(f, pattern, func(m txtopener.Match) error {
+		return report(root, m)
+	})
+	return found, err
+}
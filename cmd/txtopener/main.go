@@ -0,0 +1,73 @@
+// Command txtopener works with text files of any known encoding as if they were UTF-8 files.
+//
+// Usage:
+//
+//	txtopener <command> [arguments]
+//
+// The commands are:
+//
+//	check   report files that aren't clean UTF-8
+//	convert rewrite files in another encoding
+//	detect  print the encoding of files
+//	grep    search files decoding them first
+//
+// The convert command reads its named profiles from txtopener.yaml
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = `usage: txtopener <command> [arguments]
+
+The commands are:
+
+	check   report files that aren't clean UTF-8
+	convert rewrite files in another encoding
+	detect  print the encoding of files
+	grep    search files decoding them first
+
+Use "txtopener <command> -h" for more information about a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "check":
+		os.Exit(check(args))
+	case "convert":
+		os.Exit(convert(args))
+	case "detect":
+		os.Exit(detect(args))
+	case "grep":
+		os.Exit(grep(args))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "txtopener: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+}
+
+// errorf reports an error on stderr
+func errorf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "txtopener: "+format+"\n", args...)
+}
+
+// reportErrors reports err on stderr, one line for each of the errors it joins
+func reportErrors(err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			reportErrors(err)
+		}
+		return
+	}
+	// the errors of the package already carry the prefix
+	errorf("%s", strings.TrimPrefix(err.Error(), "txtopener: "))
+}
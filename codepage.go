@@ -0,0 +1,45 @@
+package txtopener
+
+import "strconv"
+
+// codePageLabels maps the Windows code page numbers without an obvious label to theirs.
+// The DOS and ANSI code pages are resolved as ibmNNN and windows-NNNN
+var codePageLabels = map[uint32]string{
+	866:   "ibm866",
+	932:   "shift_jis",
+	936:   "gbk",
+	949:   "euc-kr",
+	950:   "big5",
+	1200:  "utf-16le",
+	1201:  "utf-16be",
+	10000: "macintosh",
+	10007: "x-mac-cyrillic",
+	20866: "koi8-r",
+	21866: "koi8-u",
+	28591: "iso-8859-1",
+	28592: "iso-8859-2",
+	28595: "iso-8859-5",
+	28597: "iso-8859-7",
+	28605: "iso-8859-15",
+	51932: "euc-jp",
+	54936: "gb18030",
+	65001: "utf-8",
+}
+
+// codePageLabel returns the encoding label of the Windows code page cp, empty if unknown
+func codePageLabel(cp uint32) string {
+	if label, ok := codePageLabels[cp]; ok {
+		return label
+	}
+	var label string
+	switch {
+	case cp == 874 || cp >= 1250 && cp <= 1258:
+		label = "windows-" + strconv.Itoa(int(cp))
+	case cp < 1000:
+		label = "ibm" + strconv.Itoa(int(cp))
+	}
+	if _, _, err := lookup(label); err != nil {
+		return ""
+	}
+	return label
+}
@@ -0,0 +1,68 @@
+package txtopener
+
+import (
+	"context"
+	"io"
+)
+
+// NewReaderContext is like NewReaderE but its Reads stop with ctx.Err() once ctx is cancelled or
+// its deadline passes, even when r blocks. The preview is read on the first Read rather than
+// here, so the errors reading it are returned by Read as well
+func NewReaderContext(ctx context.Context, r io.Reader) io.Reader {
+	return &lazyReader{src: &ctxReader{ctx: ctx, r: r}, o: newOptions(nil)}
+}
+
+// lazyReader runs the detection on its first Read
+type lazyReader struct {
+	src io.Reader
+	o   *options
+	r   io.Reader
+	err error
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.r == nil && l.err == nil {
+		l.r, l.err = decodingReader(l.src, l.o)
+	}
+	if l.err != nil {
+		return 0, l.err
+	}
+	return l.r.Read(p)
+}
+
+// ctxReader reads from r until ctx is done. Each Read of r runs in its own goroutine so that a
+// blocked one can be abandoned, its result is discarded then
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+	buf []byte
+}
+
+type readResult struct {
+	n   int
+	err error
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if len(c.buf) < len(p) {
+		c.buf = make([]byte, len(p))
+	}
+	buf := c.buf[:len(p)]
+	done := make(chan readResult, 1)
+	go func() {
+		n, err := c.r.Read(buf)
+		done <- readResult{n, err}
+	}()
+
+	select {
+	case res := <-done:
+		return copy(p, buf[:res.n]), res.err
+	case <-c.ctx.Done():
+		// the goroutine may still write to buf
+		c.buf = nil
+		return 0, c.ctx.Err()
+	}
+}
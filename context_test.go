@@ -0,0 +1,37 @@
+package txtopener
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewReaderContext(t *testing.T) {
+	r := NewReaderContext(context.Background(), strings.NewReader(string(utf8bom)+"caf\xc3\xa9"))
+	if got, err := ioutil.ReadAll(r); err != nil || string(got) != "café" {
+		t.Errorf("got: %q, %v - expected: %q", got, err, "café")
+	}
+
+	// a reader whose writer never writes blocks the preview forever
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := NewReaderContext(ctx, pr).Read(make([]byte, 10)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got: %v - expected: %v", err, context.DeadlineExceeded)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	r = NewReaderContext(ctx, strings.NewReader(strings.Repeat("a", 20000)))
+	if _, err := r.Read(make([]byte, 10)); err != nil {
+		t.Fatalf("error en Read: %v", err)
+	}
+	cancel()
+	if _, err := ioutil.ReadAll(r); !errors.Is(err, context.Canceled) {
+		t.Errorf("got: %v - expected: %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,379 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// ConvertOptions tells ConvertFile and ConvertTree how to write the files they convert.
+// The zero value converts to UTF-8 without BOM keeping the line endings as they are
+type ConvertOptions struct {
+	// From is the label of the encoding of the files, detected for each file if empty.
+	// A BOM still wins over it
+	From string
+	// Encoding is the label of the target encoding, UTF-8 if empty. "ISO 8859-1", the name the
+	// detection gives it, is the true ISO-8859-1, while the label iso-8859-1 is windows-1252
+	Encoding string
+	// BOM starts the converted files with a byte order mark, for the encodings that have one
+	BOM bool
+	// Newline, if not empty, replaces every line ending of the converted files
+	Newline string
+	// Exclude lists the path.Match patterns of the files and directories ConvertTree leaves alone.
+	// They are matched against the slash-separated path relative to the root and against the base name
+	Exclude []string
+	// Workers is how many files ConvertTree converts at the same time, one if not positive
+	Workers int
+	// OutDir, if not empty, is the directory the converted files are written to instead of
+	// replacing the originals. ConvertTree mirrors there the structure of the tree, and the files
+	// that look binary are copied as they are
+	OutDir string
+	// EditorConfig makes the charset of the .editorconfig files that apply to each file, if any,
+	// its target encoding instead of Encoding and BOM
+	EditorConfig bool
+	// Manifest pins the encoding of the files the detection gets wrong: it maps their
+	// slash-separated paths, relative to the root for ConvertTree and as given for ConvertFile,
+	// to the label of their encoding, which wins over From
+	Manifest map[string]string
+	// GitAttributes tells what to do with the files that have a working-tree-encoding attribute
+	// in the .gitattributes files of their repository
+	GitAttributes GitAttributesPolicy
+}
+
+// GitAttributesPolicy selects how ConvertFile and ConvertTree treat the files Git re-encodes itself
+type GitAttributesPolicy int
+
+const (
+	// GitAttributesIgnore doesn't read the .gitattributes files
+	GitAttributesIgnore GitAttributesPolicy = iota
+	// GitAttributesSkip leaves alone, or copies as they are, the files with a working-tree-encoding
+	GitAttributesSkip
+	// GitAttributesFollow converts the files with a working-tree-encoding to that encoding
+	GitAttributesFollow
+)
+
+// ConvertFile rewrites the file name decoded and then encoded as opts tells.
+// The file is replaced only if its content changes, and never partially.
+// Files that look binary are left alone. With opts.OutDir the result is written to a file of
+// the same name in that directory
+func ConvertFile(name string, opts ConvertOptions) error {
+	dst := name
+	if opts.OutDir != "" {
+		dst = filepath.Join(opts.OutDir, filepath.Base(name))
+	}
+	return newConverter(opts).convert(name, dst, filepath.ToSlash(name))
+}
+
+// ConvertStream copies src to dst decoded and then encoded as opts tells, in a single streaming
+// pass, like iconv does. The options about files, Exclude, Workers, OutDir, EditorConfig,
+// Manifest and GitAttributes, don't apply
+func ConvertStream(dst io.Writer, src io.Reader, opts ConvertOptions) error {
+	label := opts.Encoding
+	if label == "" {
+		label = "utf-8"
+	}
+	target, targetName, err := lookupTarget(label)
+	if err != nil {
+		return err
+	}
+	o := newOptions(nil)
+	if opts.From != "" {
+		if _, _, err = lookup(opts.From); err != nil {
+			return err
+		}
+		o.contentType = mime.FormatMediaType("text/plain", map[string]string{"charset": opts.From})
+	}
+	r, err := decodingReader(src, o)
+	if err != nil {
+		return err
+	}
+
+	if opts.BOM {
+		for _, b := range boms {
+			if b.enc == targetName {
+				if _, err := dst.Write(b.bom); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	var ts []transform.Transformer
+	if opts.Newline != "" {
+		ts = append(ts, newlines{newline: []byte(opts.Newline), cr: true})
+	}
+	if targetName != "utf-8" {
+		ts = append(ts, target.NewEncoder())
+	}
+	var t transform.Transformer = transform.Nop
+	if len(ts) > 0 {
+		t = transform.Chain(ts...)
+	}
+	w := transform.NewWriter(dst, t)
+	_, err = io.Copy(w, r)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	// the encoders of x/text fail on the characters they don't have with an error telling
+	// the replacement byte
+	var unencodable interface{ Replacement() byte }
+	if errors.As(err, &unencodable) {
+		return fmt.Errorf("txtopener: the input can't be converted to %s: %v", targetName, err)
+	}
+	return err
+}
+
+// lookupTarget resolves the label of a target encoding. The name the detection gives the true
+// ISO-8859-1, which no label resolves to, is known as well
+func lookupTarget(label string) (encoding.Encoding, string, error) {
+	if label == "ISO 8859-1" {
+		return charmap.ISO8859_1, label, nil
+	}
+	return lookup(label)
+}
+
+// converter converts files with the options that the configuration files found next to them give
+type converter struct {
+	opts ConvertOptions
+	ec   *editorConfigs
+	ga   *gitAttributes
+}
+
+func newConverter(opts ConvertOptions) *converter {
+	c := &converter{opts: opts}
+	if opts.EditorConfig {
+		c.ec = &editorConfigs{}
+	}
+	if opts.GitAttributes != GitAttributesIgnore {
+		c.ga = &gitAttributes{}
+	}
+	return c
+}
+
+// convert converts the file src, known in the manifest as rel, and writes the result to dst.
+// The working-tree-encoding of Git wins over the charset of EditorConfig
+func (c *converter) convert(src, dst, rel string) error {
+	opts := c.opts
+	if label, ok := opts.Manifest[rel]; ok {
+		opts.From = label
+	}
+	var err error
+	if c.ec != nil {
+		if opts, err = c.ec.options(src, opts); err != nil {
+			return err
+		}
+	}
+	if c.ga != nil {
+		label, err := c.ga.workingTreeEncoding(src)
+		if err != nil {
+			return err
+		}
+		switch {
+		case label == "":
+		case opts.GitAttributes == GitAttributesSkip && dst == src:
+			return nil
+		case opts.GitAttributes == GitAttributesSkip:
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			return replaceFile(src, dst, data)
+		default:
+			opts.Encoding, opts.BOM = gitEncoding(label)
+		}
+	}
+	return convertFile(src, dst, opts)
+}
+
+// convertFile converts the file src and writes the result to dst
+func convertFile(src, dst string, opts ConvertOptions) error {
+	label := opts.Encoding
+	if label == "" {
+		label = "utf-8"
+	}
+	target, targetName, err := lookupTarget(label)
+	if err != nil {
+		return err
+	}
+	o := newOptions(nil)
+	var fromName string
+	if opts.From != "" {
+		if _, fromName, err = lookup(opts.From); err != nil {
+			return err
+		}
+		o.contentType = mime.FormatMediaType("text/plain", map[string]string{"charset": opts.From})
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	// the NULs of UTF-16 and UTF-32 without BOM are expected when told so
+	if isBinary(data) && !strings.HasPrefix(fromName, "utf-16") && !strings.HasPrefix(fromName, "utf-32") {
+		if dst != src {
+			return replaceFile(src, dst, data)
+		}
+		return nil
+	}
+
+	r, err := decodingReader(bytes.NewReader(data), o)
+	if err != nil {
+		return err
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if opts.Newline != "" {
+		text = bytes.ReplaceAll(text, []byte("\r\n"), []byte("\n"))
+		text = bytes.ReplaceAll(text, []byte("\r"), []byte("\n"))
+		text = bytes.ReplaceAll(text, []byte("\n"), []byte(opts.Newline))
+	}
+
+	out := text
+	if targetName != "utf-8" {
+		if out, err = target.NewEncoder().Bytes(text); err != nil {
+			return fmt.Errorf("txtopener: %s can't be converted to %s: %v", src, targetName, err)
+		}
+	}
+	if opts.BOM {
+		for _, b := range boms {
+			if b.enc == targetName {
+				out = append(append([]byte(nil), b.bom...), out...)
+			}
+		}
+	}
+
+	if dst == src && bytes.Equal(out, data) {
+		return nil
+	}
+	return replaceFile(src, dst, out)
+}
+
+// ConvertTree converts, as ConvertFile does, every regular file found walking the directory root.
+// The files that can't be converted don't stop the walk; their errors are joined and returned
+// once it completes
+func ConvertTree(root string, opts ConvertOptions) error {
+	var outDir string
+	if opts.OutDir != "" {
+		outDir, _ = filepath.Abs(opts.OutDir)
+	}
+
+	var files, rels []string
+	var errs []error
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if abs, _ := filepath.Abs(name); d.IsDir() && abs == outDir {
+			// an output directory inside the tree isn't part of it
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, name)
+		if rel != "." && excluded(filepath.ToSlash(rel), opts.Exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			files, rels = append(files, name), append(rels, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	c := newConverter(opts)
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	fileErrs := make([]error, len(files))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				dst := files[i]
+				if opts.OutDir != "" {
+					dst = filepath.Join(opts.OutDir, rels[i])
+				}
+				fileErrs[i] = c.convert(files[i], dst, filepath.ToSlash(rels[i]))
+			}
+		}()
+	}
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return errors.Join(append(errs, fileErrs...)...)
+}
+
+// excluded tells whether the slash-separated path rel matches any of patterns
+func excluded(rel string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isBinary tells whether data doesn't look like text, as IsBinary tells from its preview
+func isBinary(data []byte) bool {
+	if n := defaultPreview(); len(data) > n {
+		data = data[:n]
+	}
+	return IsBinary(data)
+}
+
+// replaceFile writes data to the file dst, with the permissions of src, through a temporary
+// file so dst is never left half written
+func replaceFile(src, dst string, data []byte) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(info.Mode().Perm())
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), dst)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,210 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertFile(t *testing.T) {
+	var tests = []struct {
+		content  string
+		opts     ConvertOptions
+		expected string
+	}{
+		{"caf\xe9\r\n", ConvertOptions{}, "café\r\n"},
+		{"caf\xe9\r\n", ConvertOptions{Newline: "\n"}, "café\n"},
+		{"café\n", ConvertOptions{Encoding: "windows-1252", Newline: "\r\n"}, "caf\xe9\r\n"},
+		{"café", ConvertOptions{BOM: true}, string(utf8bom) + "café"},
+		{string(utf8bom) + "é", ConvertOptions{Encoding: "utf-16le", BOM: true}, string(utf16lebom) + "\xe9\x00"},
+		{"\x00\x01\xe9", ConvertOptions{}, "\x00\x01\xe9"},
+		{"\xf0\xd2\xc9\xd7\xc5\xd4", ConvertOptions{From: "koi8-r"}, "Привет"},
+		{"café", ConvertOptions{From: "windows-1252"}, "cafÃ©"},
+		{"c\x00\xe9\x00", ConvertOptions{From: "utf-16le"}, "cé"},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "convert.txt")
+		if err := os.WriteFile(name, []byte(tt.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if err := ConvertFile(name, tt.opts); err != nil {
+			t.Errorf("%d. error en ConvertFile: %v", i, err)
+			continue
+		}
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.content, got, tt.expected)
+		}
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	var tests = []struct {
+		content  string
+		opts     ConvertOptions
+		expected string
+	}{
+		{"caf\xe9\r\n", ConvertOptions{}, "café\r\n"},
+		{"caf\xe9\r\nuno\rdos\n", ConvertOptions{Newline: "\n"}, "café\nuno\ndos\n"},
+		{"café\n", ConvertOptions{Encoding: "windows-1252", Newline: "\r\n"}, "caf\xe9\r\n"},
+		{"café", ConvertOptions{BOM: true}, string(utf8bom) + "café"},
+		{"\xf0\xd2\xc9\xd7\xc5\xd4", ConvertOptions{From: "koi8-r"}, "Привет"},
+		{"c\x00\xe9\x00", ConvertOptions{From: "utf-16le", Encoding: "iso-8859-1"}, "c\xe9"},
+	}
+
+	for i, tt := range tests {
+		var out bytes.Buffer
+		if err := ConvertStream(&out, strings.NewReader(tt.content), tt.opts); err != nil {
+			t.Errorf("%d. error en ConvertStream: %v", i, err)
+			continue
+		}
+		if got := out.String(); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.content, got, tt.expected)
+		}
+	}
+
+	if err := ConvertStream(ioutil.Discard, strings.NewReader("π"), ConvertOptions{Encoding: "windows-1252"}); err == nil {
+		t.Errorf("π converted to windows-1252")
+	}
+	if err := ConvertStream(ioutil.Discard, strings.NewReader("a"), ConvertOptions{From: "nope"}); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("unknown source encoding -> got: %v - expected: %v", err, ErrUnknownEncoding)
+	}
+}
+
+func TestConvertTree(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":          "caf\xe9",
+		"sub/b.txt":      "na\xefve",
+		"vendor/c.txt":   "ol\xe9",
+		"sub/keep.latin": "ol\xe9",
+		"sub/bad.txt":    "π",
+	}
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := ConvertTree(dir, ConvertOptions{Encoding: "utf-8", Exclude: []string{"vendor", "*.latin"}, Workers: 3})
+	if err != nil {
+		t.Errorf("error en ConvertTree: %v", err)
+	}
+	if err := ConvertTree(dir, ConvertOptions{Encoding: "latin1", Exclude: []string{"a.txt", "b.txt", "vendor", "*.latin"}}); err == nil {
+		t.Errorf("expected an error converting sub/bad.txt")
+	}
+
+	expected := map[string]string{
+		"a.txt":          "café",
+		"sub/b.txt":      "naïve",
+		"vendor/c.txt":   "ol\xe9",
+		"sub/keep.latin": "ol\xe9",
+		"sub/bad.txt":    "π",
+	}
+	for name, content := range expected {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("%s -> got: %q - expected: %q", name, got, content)
+		}
+	}
+}
+
+func TestConvertTreeOutDir(t *testing.T) {
+	dir := t.TempDir()
+	src, out := filepath.Join(dir, "src"), filepath.Join(dir, "src", "out")
+	files := map[string]string{
+		"a.txt":     "caf\xe9",
+		"sub/b.txt": "na\xefve",
+		"sub/c.bin": "\x00\xe9",
+	}
+	for name, content := range files {
+		name = filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := ConvertTree(src, ConvertOptions{OutDir: out}); err != nil {
+			t.Errorf("error en ConvertTree: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		"a.txt":         "caf\xe9",
+		"sub/b.txt":     "na\xefve",
+		"out/a.txt":     "café",
+		"out/sub/b.txt": "naïve",
+		"out/sub/c.bin": "\x00\xe9",
+	}
+	for name, content := range expected {
+		got, err := os.ReadFile(filepath.Join(src, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s -> got: %q - expected: %q", name, got, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "out")); err == nil {
+		t.Errorf("the output directory was converted into itself")
+	}
+}
+
+func TestConvertTreeManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.txt":     "caf\xe9",
+		"sub/b.txt": "\xcf\xf0\xe8\xe2\xe5\xf2",
+		"sub/c.txt": "\xf0\xd2\xc9\xd7\xc5\xd4",
+	}
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest := map[string]string{"sub/b.txt": "windows-1251", "sub/c.txt": "koi8-r"}
+	if err := ConvertTree(dir, ConvertOptions{From: "latin1", Manifest: manifest}); err != nil {
+		t.Errorf("error en ConvertTree: %v", err)
+	}
+
+	expected := map[string]string{
+		"a.txt":     "café",
+		"sub/b.txt": "Привет",
+		"sub/c.txt": "Привет",
+	}
+	for name, want := range expected {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s -> got: %q - expected: %q", name, got, want)
+		}
+	}
+}
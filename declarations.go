@@ -0,0 +1,56 @@
+package txtopener
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding"
+)
+
+// xmlEncodingDecl matches the encoding declaration of the XML declaration, its first attributes
+var xmlEncodingDecl = regexp.MustCompile(`^<\?xml[ \t\r\n]+version[ \t\r\n]*=[ \t\r\n]*(?:"[^"]*"|'[^']*')[ \t\r\n]+encoding[ \t\r\n]*=[ \t\r\n]*(?:"([A-Za-z][A-Za-z0-9._-]*)"|'([A-Za-z][A-Za-z0-9._-]*)')`)
+
+// xmlDeclaration returns the encoding declared by the XML declaration content starts with, such
+// as <?xml version="1.0" encoding="shift_jis"?>. Like a meta element, a declared UTF-16 can
+// only be a mistake in ASCII-compatible content and is taken for UTF-8
+func xmlDeclaration(content []byte) (encoding.Encoding, string) {
+	if !bytes.HasPrefix(content, []byte("<?xml")) {
+		return nil, ""
+	}
+	m := xmlEncodingDecl.FindSubmatch(content)
+	if m == nil {
+		return nil, ""
+	}
+	label := string(m[1]) + string(m[2])
+	e, name := lookupLabel(label)
+	if strings.HasPrefix(name, "utf-16") {
+		return encoding.Nop, "utf-8"
+	}
+	return e, name
+}
+
+// cssCharsetRule is the start of the @charset rule, which CSS only takes written exactly so
+var cssCharsetRule = []byte(`@charset "`)
+
+// cssCharset returns the encoding declared by the @charset rule content starts with, such as
+// @charset "windows-1250";. As CSS Syntax tells, the rule is looked for in the first 1024 bytes
+// and a declared UTF-16 is taken for UTF-8
+func cssCharset(content []byte) (encoding.Encoding, string) {
+	if !bytes.HasPrefix(content, cssCharsetRule) {
+		return nil, ""
+	}
+	if len(content) > 1024 {
+		content = content[:1024]
+	}
+	rest := content[len(cssCharsetRule):]
+	end := bytes.Index(rest, []byte(`";`))
+	if end < 0 {
+		return nil, ""
+	}
+	e, name := lookupLabel(string(rest[:end]))
+	if strings.HasPrefix(name, "utf-16") {
+		return encoding.Nop, "utf-8"
+	}
+	return e, name
+}
@@ -0,0 +1,49 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestXMLDeclaration(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{`<?xml version="1.0" encoding="shift_jis"?><a>` + "\x83e\x83X\x83g</a>", `<?xml version="1.0" encoding="shift_jis"?><a>テスト</a>`},
+		{"<?xml version='1.0'\n  encoding = 'windows-1251' standalone='yes'?><a>\xcf\xf0\xe8</a>", "<?xml version='1.0'\n  encoding = 'windows-1251' standalone='yes'?><a>При</a>"},
+		{`<?xml version="1.0" encoding="UTF-16"?><a>café</a>`, `<?xml version="1.0" encoding="UTF-16"?><a>café</a>`},
+		{`<?xml version="1.0" encoding="no-such"?><a>caf` + "\xe9</a>", `<?xml version="1.0" encoding="no-such"?><a>café</a>`},
+		{` <?xml version="1.0" encoding="koi8-r"?><a>caf` + "\xe9</a>", ` <?xml version="1.0" encoding="koi8-r"?><a>café</a>`},
+		{`<?xml version="1.0"?><a>caf` + "\xe9</a>", `<?xml version="1.0"?><a>café</a>`},
+	}
+
+	for i, tt := range tests {
+		got, err := ioutil.ReadAll(NewReader(strings.NewReader(tt.feed)))
+		if err != nil || string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q", i, tt.feed, got, err, tt.expected)
+		}
+	}
+}
+
+func TestCSSCharset(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{"@charset \"windows-1250\";\np::before { content: \"\x9au\x9ei\" }", "@charset \"windows-1250\";\np::before { content: \"šuži\" }"},
+		{"@charset \"koi8-r\";a{b:\"\xf0\xd2\xc9\"}", "@charset \"koi8-r\";a{b:\"При\"}"},
+		{"@charset \"utf-16\";a{b:\"café\"}", "@charset \"utf-16\";a{b:\"café\"}"},
+		{"@charset 'koi8-r';a{b:\"caf\xe9\"}", "@charset 'koi8-r';a{b:\"café\"}"},
+		{"@CHARSET \"koi8-r\";a{b:\"caf\xe9\"}", "@CHARSET \"koi8-r\";a{b:\"café\"}"},
+		{" @charset \"koi8-r\";a{b:\"caf\xe9\"}", " @charset \"koi8-r\";a{b:\"café\"}"},
+	}
+
+	for i, tt := range tests {
+		got, err := ioutil.ReadAll(NewReader(strings.NewReader(tt.feed)))
+		if err != nil || string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q", i, tt.feed, got, err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package txtopener
+
+import (
+	"sync"
+
+	"golang.org/x/text/encoding"
+)
+
+// Detector guesses the encoding of content that declares none, that is, content without
+// a BOM, a Content-Type charset or a meta declaration that isn't valid UTF-8 either
+type Detector interface {
+	// Detect returns the encoding it believes preview is written in, its name and how
+	// confident it is about it, from 0 to 1. A nil encoding means no claim is made
+	Detect(preview []byte) (e encoding.Encoding, name string, confidence float64)
+}
+
+// DetectorFunc adapts an ordinary function to the Detector interface
+type DetectorFunc func(preview []byte) (e encoding.Encoding, name string, confidence float64)
+
+// Detect calls f(preview)
+func (f DetectorFunc) Detect(preview []byte) (encoding.Encoding, string, float64) {
+	return f(preview)
+}
+
+// WithDetector adds d to the chain of detectors consulted before falling back to the default encoding.
+// The claim with the highest confidence wins; on a tie the detector added first does
+func WithDetector(d Detector) Option {
+	return func(o *options) {
+		o.detectors = append(o.detectors, d)
+	}
+}
+
+// detect runs the chain of detectors over content and returns the best claim, if any
+func detect(detectors []Detector, content []byte) (e encoding.Encoding, name string, confidence float64) {
+	for _, d := range detectors {
+		de, dname, dconf := d.Detect(content)
+		if dconf != dconf {
+			// NaN compares false, a first claim with it could never be beaten
+			dconf = 0
+		}
+		if de != nil && (e == nil || dconf > confidence) {
+			e, name, confidence = de, dname, dconf
+		}
+	}
+	return e, name, confidence
+}
+
+// registry holds the detectors registered with RegisterDetector, in order of registration
+var registry struct {
+	sync.RWMutex
+	names     []string
+	detectors []Detector
+}
+
+// RegisterDetector adds d, under name, to the detectors every reader consults after those given
+// WithDetector, so that ICU bindings or sniffers for the formats of a domain plug into the
+// package. Registering again a name replaces its detector, in the same place of the chain, and
+// registering nil removes it. It is safe for concurrent use
+func RegisterDetector(name string, d Detector) {
+	registry.Lock()
+	defer registry.Unlock()
+	for i, n := range registry.names {
+		if n != name {
+			continue
+		}
+		if d == nil {
+			registry.names = append(registry.names[:i:i], registry.names[i+1:]...)
+			registry.detectors = append(registry.detectors[:i:i], registry.detectors[i+1:]...)
+		} else {
+			registry.detectors[i] = d
+		}
+		return
+	}
+	if d != nil {
+		registry.names = append(registry.names, name)
+		registry.detectors = append(registry.detectors, d)
+	}
+}
+
+// allDetectors returns the chain of detectors of the reader: those given WithDetector followed
+// by the registered ones
+func (o *options) allDetectors() []Detector {
+	registry.RLock()
+	defer registry.RUnlock()
+	if len(registry.detectors) == 0 {
+		return o.detectors
+	}
+	return append(o.detectors[:len(o.detectors):len(o.detectors)], registry.detectors...)
+}
@@ -0,0 +1,42 @@
+package txtopener
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// claim returns a detector that always claims the encoding e, named name, with confidence
+func claim(e encoding.Encoding, name string, confidence float64) Detector {
+	return DetectorFunc(func([]byte) (encoding.Encoding, string, float64) {
+		return e, name, confidence
+	})
+}
+
+func TestRegisterDetector(t *testing.T) {
+	defer RegisterDetector("test-koi8", nil)
+	defer RegisterDetector("test-1250", nil)
+
+	var tests = []struct {
+		register func()
+		opts     []Option
+		expected string
+	}{
+		{func() {}, nil, "ISO 8859-1"},
+		{func() { RegisterDetector("test-koi8", claim(charmap.KOI8R, "koi8-r", 0.5)) }, nil, "koi8-r"},
+		{func() { RegisterDetector("test-1250", claim(charmap.Windows1250, "windows-1250", 0.9)) }, nil, "windows-1250"},
+		{func() {}, []Option{WithDetector(claim(charmap.Windows1251, "windows-1251", 0.9))}, "windows-1251"},
+		{func() { RegisterDetector("test-1250", claim(charmap.Windows1250, "windows-1250", 0.1)) }, nil, "koi8-r"},
+		{func() { RegisterDetector("test-koi8", nil) }, nil, "windows-1250"},
+		{func() { RegisterDetector("test-1250", nil) }, nil, "ISO 8859-1"},
+	}
+
+	for i, tt := range tests {
+		tt.register()
+		_, name, _ := DetectEncoding([]byte("caf\xe9"), "", tt.opts...)
+		if name != tt.expected {
+			t.Errorf("%d. got: %s - expected: %s", i, name, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package txtopener
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNondeterministic is returned by the readers created WithDeterministic when a detector
+// claims something different each time it is asked about the same preview
+var ErrNondeterministic = errors.New("txtopener: nondeterministic detector")
+
+// WithDeterministic asserts that the detection is deterministic, so that repeated runs over the
+// same input always agree. The detection of the package is: it follows a fixed precedence,
+// documented in DetectEncoding, and breaks every tie in favor of the first candidate in a fixed
+// order, never depending on the iteration of maps. The detectors added with WithDetector or
+// RegisterDetector are checked by asking each of them twice, and the reader fails with ErrNondeterministic if any answers differently
+func WithDeterministic() Option {
+	return func(o *options) {
+		o.deterministic = true
+	}
+}
+
+// checkDeterministic asks twice every detector about preview and returns an error for the
+// first one that gives different answers
+func checkDeterministic(detectors []Detector, preview []byte) error {
+	for i, d := range detectors {
+		_, name1, conf1 := d.Detect(preview)
+		_, name2, conf2 := d.Detect(preview)
+		if name1 != name2 || conf1 != conf2 {
+			return fmt.Errorf("%w %d: %s (%g), then %s (%g)", ErrNondeterministic, i, name1, conf1, name2, conf2)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDeterministic(t *testing.T) {
+	samples := []string{greekSample, polishSample, czechSample, hungarianSample, turkishSample, lithuanianSample, latvianSample, westernSample}
+	encodings := []encoding.Encoding{charmap.ISO8859_7, charmap.Windows1250, charmap.ISO8859_2, charmap.Windows1257, charmap.Windows1254}
+
+	for i, sample := range samples {
+		for _, e := range encodings {
+			content, err := e.NewEncoder().Bytes([]byte(sample))
+			if err != nil {
+				continue
+			}
+			_, first, _ := DetectEncoding(content, "")
+			for run := 0; run < 20; run++ {
+				if _, name, _ := DetectEncoding(content, ""); name != first {
+					t.Errorf("%d. run %d -> got: %s - expected: %s", i, run, name, first)
+				}
+			}
+		}
+	}
+
+	calls := 0
+	flaky := DetectorFunc(func(preview []byte) (encoding.Encoding, string, float64) {
+		calls++
+		if calls%2 == 0 {
+			return charmap.Windows1251, "windows-1251", 0.5
+		}
+		return charmap.KOI8R, "koi8-r", 0.5
+	})
+	steady := DetectorFunc(func(preview []byte) (encoding.Encoding, string, float64) {
+		return charmap.KOI8R, "koi8-r", 0.5
+	})
+
+	if _, err := NewReaderOpts(strings.NewReader("\xf0\xd2\xc9\xd7\xc5\xd4"), WithDetector(flaky), WithDeterministic()); !errors.Is(err, ErrNondeterministic) {
+		t.Errorf("got: %v - expected: %v", err, ErrNondeterministic)
+	}
+	r, err := NewReaderOpts(strings.NewReader("\xf0\xd2\xc9\xd7\xc5\xd4"), WithDetector(steady), WithDeterministic())
+	if err != nil {
+		t.Fatalf("error en NewReaderOpts: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "Привет" {
+		t.Errorf("got: %q - expected: %q", got, "Привет")
+	}
+}
@@ -0,0 +1,243 @@
+package txtopener
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// editorConfigFile is the name of the EditorConfig files, see https://editorconfig.org
+const editorConfigFile = ".editorconfig"
+
+// editorCharsets maps the charset values of EditorConfig to the target encoding and BOM.
+// UTF-16 files get a BOM, as the editors that write them do. latin1 is the true ISO-8859-1,
+// by the name the detection gives it, as iso-8859-1 is windows-1252 for the WHATWG
+var editorCharsets = map[string]struct {
+	label string
+	bom   bool
+}{
+	"latin1":    {"ISO 8859-1", false},
+	"utf-8":     {"utf-8", false},
+	"utf-8-bom": {"utf-8", true},
+	"utf-16be":  {"utf-16be", true},
+	"utf-16le":  {"utf-16le", true},
+}
+
+// editorConfigs reads and caches the EditorConfig files of the directories, safe for concurrent use
+type editorConfigs struct {
+	mu    sync.Mutex
+	files map[string]*editorConfig
+}
+
+// editorConfig is a parsed EditorConfig file
+type editorConfig struct {
+	root     bool
+	sections []editorSection
+}
+
+// editorSection is a section of an EditorConfig file: the properties of the files its glob
+// matches. Sections with invalid globs have none
+type editorSection struct {
+	glob  *regexp.Regexp
+	props map[string]string
+}
+
+// options returns opts with the target encoding of the file name replaced with the charset
+// that applies to it, if any
+func (c *editorConfigs) options(name string, opts ConvertOptions) (ConvertOptions, error) {
+	charset, err := c.charset(name)
+	if err != nil {
+		return opts, err
+	}
+	if cs, ok := editorCharsets[charset]; ok {
+		opts.Encoding, opts.BOM = cs.label, cs.bom
+	}
+	return opts, nil
+}
+
+// charset returns the charset property of the file name, looking for the EditorConfig files from
+// its directory up until one with root = true. The closer files win
+func (c *editorConfigs) charset(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	var charset string
+	for dir := filepath.Dir(abs); ; {
+		ec, err := c.load(dir)
+		if err != nil {
+			return "", err
+		}
+		if ec != nil && charset == "" {
+			rel, _ := filepath.Rel(dir, abs)
+			rel = "/" + filepath.ToSlash(rel)
+			for _, s := range ec.sections {
+				if v, ok := s.props["charset"]; ok && s.glob != nil && s.glob.MatchString(rel) {
+					charset = v
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if ec != nil && ec.root || parent == dir || charset != "" {
+			break
+		}
+		dir = parent
+	}
+	return charset, nil
+}
+
+// load returns the EditorConfig file of dir, nil if there is none
+func (c *editorConfigs) load(dir string) (*editorConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ec, ok := c.files[dir]; ok {
+		return ec, nil
+	}
+	if c.files == nil {
+		c.files = map[string]*editorConfig{}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, editorConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		c.files[dir] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ec := parseEditorConfig(data)
+	c.files[dir] = ec
+	return ec, nil
+}
+
+// parseEditorConfig parses the INI-like format of EditorConfig. Keys and the values of the known
+// properties are case insensitive
+func parseEditorConfig(data []byte) *editorConfig {
+	ec := &editorConfig{}
+	var section *editorSection
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			glob, _ := editorGlob(line[1 : len(line)-1])
+			ec.sections = append(ec.sections, editorSection{glob: glob, props: map[string]string{}})
+			section = &ec.sections[len(ec.sections)-1]
+		default:
+			i := strings.IndexAny(line, "=:")
+			if i < 0 {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(line[:i]))
+			value := strings.ToLower(strings.TrimSpace(line[i+1:]))
+			if section != nil {
+				section.props[key] = value
+			} else if key == "root" {
+				ec.root = value == "true"
+			}
+		}
+	}
+	return ec
+}
+
+// editorGlob compiles an EditorConfig glob into a regular expression matching the paths, starting
+// with a slash, relative to the directory of the file. Globs without a slash match at any depth
+func editorGlob(glob string) (*regexp.Regexp, error) {
+	if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	} else if glob[0] != '/' {
+		glob = "/" + glob
+	}
+
+	var re strings.Builder
+	re.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				re.WriteString(".*")
+				i++
+			} else {
+				re.WriteString("[^/]*")
+			}
+		case '?':
+			re.WriteString("[^/]")
+		case '[':
+			j := strings.IndexByte(glob[i:], ']')
+			if j < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += j
+		case '{':
+			j := strings.IndexByte(glob[i:], '}')
+			if j > 0 {
+				if alts, ok := numericRange(glob[i+1 : i+j]); ok {
+					re.WriteString(alts)
+					i += j
+					continue
+				}
+			}
+			if j < 0 || !strings.Contains(glob[i:i+j], ",") {
+				re.WriteString(`\{`)
+				continue
+			}
+			braces++
+			re.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				re.WriteString(`\}`)
+				continue
+			}
+			braces--
+			re.WriteString(")")
+		case ',':
+			if braces == 0 {
+				re.WriteString(",")
+				continue
+			}
+			re.WriteString("|")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				re.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
+
+// numericRange returns the alternation matching the integers of a {num1..num2} range
+func numericRange(s string) (string, bool) {
+	bounds := strings.Split(s, "..")
+	if len(bounds) != 2 {
+		return "", false
+	}
+	lo, err1 := strconv.Atoi(bounds[0])
+	hi, err2 := strconv.Atoi(bounds[1])
+	if err1 != nil || err2 != nil || lo > hi || hi-lo > 1000 {
+		return "", false
+	}
+	alts := make([]string, 0, hi-lo+1)
+	for n := lo; n <= hi; n++ {
+		alts = append(alts, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(alts, "|") + ")", true
+}
@@ -0,0 +1,82 @@
+package txtopener
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorGlob(t *testing.T) {
+	var tests = []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"*", "/a.txt", true},
+		{"*.txt", "/sub/a.txt", true},
+		{"/*.txt", "/sub/a.txt", false},
+		{"sub/*.txt", "/sub/a.txt", true},
+		{"lib/**.js", "/lib/x/y.js", true},
+		{"*.{js,py}", "/a.py", true},
+		{"*.{js,py}", "/a.go", false},
+		{"file[0-9].txt", "/file7.txt", true},
+		{"file[!0-9].txt", "/file7.txt", false},
+		{"v{1..3}.txt", "/v2.txt", true},
+		{"v{1..3}.txt", "/v4.txt", false},
+		{"a?c", "/a/c", false},
+	}
+
+	for i, tt := range tests {
+		re, err := editorGlob(tt.glob)
+		if err != nil {
+			t.Errorf("%d. error en editorGlob: %v", i, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.matches {
+			t.Errorf("%d. feeded: %q, %q -> got: %v - expected: %v", i, tt.glob, tt.path, got, tt.matches)
+		}
+	}
+}
+
+func TestConvertTreeEditorConfig(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".editorconfig":     "root = true\n\n[*]\ncharset = utf-8\n\n[*.bat]\ncharset = latin1\n",
+		"a.txt":             "caf\xe9",
+		"run.bat":           "caf\xe9",
+		"c1.bat":            "a\u0085b",
+		"win/.editorconfig": "[*.txt]\ncharset = utf-8-bom\n",
+		"win/b.txt":         "caf\xe9",
+		"win/c.md":          "caf\xe9",
+	}
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ConvertTree(dir, ConvertOptions{Encoding: "utf-16le", EditorConfig: true}); err != nil {
+		t.Errorf("error en ConvertTree: %v", err)
+	}
+
+	expected := map[string]string{
+		"a.txt":     "café",
+		"run.bat":   "caf\xe9",
+		"c1.bat":    "a\x85b",
+		"win/b.txt": string(utf8bom) + "café",
+		"win/c.md":  "café",
+	}
+	for name, want := range expected {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s -> got: %q - expected: %q", name, got, want)
+		}
+	}
+}
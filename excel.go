@@ -0,0 +1,37 @@
+package txtopener
+
+import (
+	"encoding/csv"
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+// NewExcelWriter returns a writer for CSV files meant for Excel, which takes UTF-8 without a BOM
+// for the ANSI code page: the UTF-8 text written to it is written to w starting with the UTF-8
+// BOM and with CRLF line endings. Close flushes what is pending, it doesn't close w
+func NewExcelWriter(w io.Writer) io.WriteCloser {
+	t := transform.Chain(&dropBOM{}, newlines{newline: []byte("\r\n")})
+	return transform.NewWriter(&prefixWriter{w: w, prefix: []byte(utf8BOM)}, t)
+}
+
+// CSVWriter is a csv.Writer writing through NewExcelWriter
+type CSVWriter struct {
+	*csv.Writer
+	w io.WriteCloser
+}
+
+// NewExcelCSVWriter returns a CSVWriter writing the records to w for Excel
+func NewExcelCSVWriter(w io.Writer) *CSVWriter {
+	ew := NewExcelWriter(w)
+	return &CSVWriter{csv.NewWriter(ew), ew}
+}
+
+// Close flushes the records and the writer underneath, it doesn't close w
+func (c *CSVWriter) Close() error {
+	c.Flush()
+	if err := c.Error(); err != nil {
+		return err
+	}
+	return c.w.Close()
+}
@@ -0,0 +1,31 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestNewExcelWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewExcelWriter(&buf)
+	io.WriteString(w, string(utf8bom)+"nombre;año\n")
+	io.WriteString(w, "niño;2024\r\n")
+	if err := w.Close(); err != nil {
+		t.Errorf("error en Close: %v", err)
+	}
+	if expected := string(utf8bom) + "nombre;año\r\nniño;2024\r\n"; buf.String() != expected {
+		t.Errorf("got: %q - expected: %q", buf.String(), expected)
+	}
+
+	buf.Reset()
+	cw := NewExcelCSVWriter(&buf)
+	cw.Write([]string{"nombre", "nota"})
+	cw.Write([]string{"niño", "dos\nlíneas"})
+	if err := cw.Close(); err != nil {
+		t.Errorf("error en Close: %v", err)
+	}
+	if expected := string(utf8bom) + "nombre,nota\r\nniño,\"dos\r\nlíneas\"\r\n"; buf.String() != expected {
+		t.Errorf("got: %q - expected: %q", buf.String(), expected)
+	}
+}
@@ -0,0 +1,78 @@
+package txtopener
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding"
+)
+
+// extensionHints holds the encodings files are usually written with by their extension, in
+// lowercase with the dot. They are consulted for the files named WithFilename when their content
+// goes beyond ASCII and nothing but the fallback would tell its encoding
+var extensionHints = struct {
+	sync.RWMutex
+	labels map[string]string
+}{labels: map[string]string{
+	// subtitles, most of them written by Windows tools
+	".srt": "windows-1252",
+	".sub": "windows-1252",
+	// exports of SAP and spreadsheets
+	".csv": "iso-8859-1",
+	// the files of the Windows registry editor
+	".reg": "utf-16le",
+	// the release notes of the DOS scene, drawn with the box characters of CP437
+	".nfo": "ibm437",
+}}
+
+// extensionConfidence is that of an encoding hinted by the extension of the file
+const extensionConfidence = 0.5
+
+// WithFilename tells the reader the name of the file it reads, whose extension hints the encoding
+// of content that goes beyond ASCII when nothing but the fallback would tell it. Open, ReadFile,
+// DetectFile, OpenFS and WalkText pass the names of their files
+func WithFilename(name string) Option {
+	return func(o *options) {
+		o.filename = name
+	}
+}
+
+// RegisterExtensionHint makes label the encoding hinted by the extension ext of the files, with
+// or without its dot and regardless of case, replacing the hint of the package if any. An empty
+// label removes the hint. It returns ErrUnknownEncoding if label can't be resolved. It is safe
+// for concurrent use
+func RegisterExtensionHint(ext, label string) error {
+	if label != "" {
+		if _, _, err := lookup(label); err != nil {
+			return err
+		}
+	}
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	extensionHints.Lock()
+	defer extensionHints.Unlock()
+	if label == "" {
+		delete(extensionHints.labels, ext)
+	} else {
+		extensionHints.labels[ext] = label
+	}
+	return nil
+}
+
+// extensionHint returns the encoding hinted by the extension of the file the reader reads and
+// its name, nil if there is none
+func (o *options) extensionHint() (encoding.Encoding, string) {
+	if o.filename == "" {
+		return nil, ""
+	}
+	extensionHints.RLock()
+	label, ok := extensionHints.labels[strings.ToLower(filepath.Ext(o.filename))]
+	extensionHints.RUnlock()
+	if !ok {
+		return nil, ""
+	}
+	return lookupLabel(label)
+}
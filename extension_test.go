@@ -0,0 +1,79 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestExtensionHint(t *testing.T) {
+	cyrillic, _ := charmap.Windows1251.NewEncoder().String(russianSample)
+	var tests = []struct {
+		name     string
+		content  string
+		expected Report
+	}{
+		{"movie.srt", "\x93caf\xe9\x94", Report{Encoding: "windows-1252", Confidence: 0.5, Evidence: EvidenceExtension}},
+		{"MOVIE.SRT", "\x93caf\xe9\x94", Report{Encoding: "windows-1252", Confidence: 0.5, Evidence: EvidenceExtension}},
+		{"movie.txt", "\x93caf\xe9\x94", Report{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+		{"movie.srt", "plain ascii", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+		{"movie.srt", "caf\xc3\xa9", Report{Encoding: "utf-8", Confidence: 0.9, Evidence: EvidenceUTF8}},
+		{"keys.reg", "R\x00E\x00G\x00", Report{Encoding: "utf-16le", Confidence: 0.5, Evidence: EvidenceExtension}},
+		{"keys.reg", "REGEDIT4", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(name, []byte(tt.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		got, err := DetectFile(name)
+		if err != nil {
+			t.Errorf("%d. error en DetectFile: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. %s -> got: %+v - expected: %+v", i, tt.name, got, tt.expected)
+		}
+	}
+
+	// the language models decide before the extension
+	r, err := NewReaderOpts(strings.NewReader(cyrillic), WithFilename("movie.srt"))
+	if err != nil {
+		t.Fatalf("error en NewReaderOpts: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != russianSample {
+		t.Errorf("got: %q - expected: %q", got, russianSample)
+	}
+}
+
+func TestRegisterExtensionHint(t *testing.T) {
+	defer RegisterExtensionHint(".dat", "")
+
+	var tests = []struct {
+		ext, label string
+		err        error
+		expected   string
+	}{
+		{".dat", "koi8-r", nil, "koi8-r"},
+		{"DAT", "windows-1250", nil, "windows-1250"},
+		{".dat", "no-such-encoding", ErrUnknownEncoding, "windows-1250"},
+		{"dat", "", nil, "ISO 8859-1"},
+	}
+
+	for i, tt := range tests {
+		if err := RegisterExtensionHint(tt.ext, tt.label); !errors.Is(err, tt.err) {
+			t.Errorf("%d. got error: %v - expected: %v", i, err, tt.err)
+		}
+		_, name, _ := DetectEncoding([]byte("caf\xe9"), "", WithFilename("data/export.dat"))
+		if name != tt.expected {
+			t.Errorf("%d. got: %s - expected: %s", i, name, tt.expected)
+		}
+	}
+}
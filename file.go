@@ -0,0 +1,57 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// ReadFile is like os.ReadFile but it returns the content of the named file converted to UTF-8
+// without BOM
+func ReadFile(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decodingReader(bytes.NewReader(data), newOptions([]Option{WithFilename(name)}))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// ReadString is like ReadFile but it returns the content as a string
+func ReadString(name string) (string, error) {
+	b, err := ReadFile(name)
+	return string(b), err
+}
+
+// ReadLines returns the lines of the named file converted to UTF-8, without their line
+// endings: LF, CRLF or CR. A line ending at the end of the file doesn't start another line
+func ReadLines(name string) ([]string, error) {
+	s, err := ReadString(name)
+	if err != nil || s == "" {
+		return nil, err
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n"), nil
+}
+
+// WithFile opens the named file, calls fn with a reader that converts its content to UTF-8
+// without BOM and closes the file, even if fn panics. It returns the error of fn or, if there
+// is none, the error opening, reading the preview of or closing the file
+func WithFile(name string, fn func(io.Reader) error) (err error) {
+	rc, err := Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := rc.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	return fn(rc)
+}
@@ -0,0 +1,110 @@
+package txtopener
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	var tests = []struct {
+		content  string
+		expected string
+	}{
+		{"caf\xe9", "café"},
+		{string(utf8bom) + "año", "año"},
+		{string(utf16bebom) + "\x00a\x00\xf1\x00o", "año"},
+		{"", ""},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "read.txt")
+		if err := os.WriteFile(name, []byte(tt.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadFile(name)
+		if err != nil {
+			t.Errorf("%d. error en ReadFile: %v", i, err)
+			continue
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.content, got, tt.expected)
+		}
+	}
+
+	if _, err := ReadFile(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("expected an error reading a missing file")
+	}
+}
+
+func TestReadLines(t *testing.T) {
+	var tests = []struct {
+		content  string
+		expected []string
+	}{
+		{"uno\r\ndos\ntres\rcuatro", []string{"uno", "dos", "tres", "cuatro"}},
+		{"caf\xe9\n\nfin\n", []string{"café", "", "fin"}},
+		{string(utf8bom) + "\n", []string{""}},
+		{"", nil},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "lines.txt")
+		if err := os.WriteFile(name, []byte(tt.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadLines(name)
+		if err != nil {
+			t.Errorf("%d. error en ReadLines: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.content, got, tt.expected)
+		}
+	}
+
+	name := filepath.Join(dir, "string.txt")
+	if err := os.WriteFile(name, []byte("caf\xe9\r\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := ReadString(name); err != nil || s != "café\r\n" {
+		t.Errorf("ReadString -> got: %q, %v - expected: %q", s, err, "café\r\n")
+	}
+}
+
+func TestWithFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "with.txt")
+	if err := os.WriteFile(name, []byte("caf\xe9"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	var file io.Reader
+	err := WithFile(name, func(r io.Reader) error {
+		file = r
+		got, err := ioutil.ReadAll(r)
+		if string(got) != "café" {
+			t.Errorf("got: %q - expected: %q", got, "café")
+		}
+		return err
+	})
+	if err != nil {
+		t.Errorf("error en WithFile: %v", err)
+	}
+	if err := file.(io.Closer).Close(); err == nil {
+		t.Errorf("the file wasn't closed")
+	}
+
+	failed := errors.New("failed")
+	if err := WithFile(name, func(io.Reader) error { return failed }); err != failed {
+		t.Errorf("got: %v - expected: %v", err, failed)
+	}
+	if err := WithFile(filepath.Join(t.TempDir(), "missing.txt"), func(io.Reader) error { return nil }); err == nil {
+		t.Errorf("expected an error opening a missing file")
+	}
+}
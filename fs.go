@@ -0,0 +1,185 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+)
+
+// OpenFS is like Open but it opens the named file of fsys, such as an embed.FS, a *zip.Reader
+// or a fstest.MapFS
+func OpenFS(fsys fs.FS, name string) (io.ReadCloser, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decodingReader(file, newOptions([]Option{WithFilename(name)}))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return closing(r, file), nil
+}
+
+// WalkText walks the tree of fsys rooted at root like fs.WalkDir and calls fn for each regular
+// file with a reader that converts its content to UTF-8 without BOM and the report of its
+// encoding. The file is closed when fn returns. The errors of fn and of the walk stop it, except
+// fs.SkipDir and fs.SkipAll, which are handled as in fs.WalkDir
+func WalkText(fsys fs.FS, root string, fn func(path string, r io.Reader, rep Report) error) error {
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		file, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r, res, err := decodingResult(file, newOptions([]Option{WithFilename(name)}))
+		if err != nil {
+			return &fs.PathError{Op: "read", Path: name, Err: err}
+		}
+		return fn(name, r, res.report())
+	})
+}
+
+// TranscodeFS returns a file system whose files are those of fsys converted to UTF-8 without
+// BOM, so that legacy content can be handed to html/template, http.FileServer or anything
+// reading an fs.FS. Files that look binary are left as they are. Each file is read whole when
+// opened, its Stat and the Info of its directory entry report the size after the conversion
+// and it can seek
+func TranscodeFS(fsys fs.FS) fs.FS {
+	return transcodeFS{fsys}
+}
+
+type transcodeFS struct {
+	fsys fs.FS
+}
+
+func (t transcodeFS) Open(name string) (fs.File, error) {
+	file, err := t.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if dir, ok := file.(fs.ReadDirFile); ok {
+			return transcodeDir{dir, t, name}, nil
+		}
+		return file, nil
+	}
+
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err == nil && !isBinary(data) {
+		data, err = decodeAll(data, name)
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &memFile{Reader: bytes.NewReader(data), info: sizedInfo{info, int64(len(data))}}, nil
+}
+
+// transcodeDir is a directory of a transcodeFS: the Info of its entries tells the size of the
+// files after the conversion, which is found out only when asked
+type transcodeDir struct {
+	fs.ReadDirFile
+	t    transcodeFS
+	name string
+}
+
+func (d transcodeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := d.ReadDirFile.ReadDir(n)
+	for i, e := range entries {
+		if !e.IsDir() {
+			entries[i] = transcodeEntry{e, d.t, path.Join(d.name, e.Name())}
+		}
+	}
+	return entries, err
+}
+
+type transcodeEntry struct {
+	fs.DirEntry
+	t    transcodeFS
+	name string
+}
+
+func (e transcodeEntry) Info() (fs.FileInfo, error) {
+	file, err := e.t.Open(e.name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
+
+// decodeAll returns data, the content of the file named name, converted to UTF-8 without BOM
+func decodeAll(data []byte, name string) ([]byte, error) {
+	r, err := decodingReader(bytes.NewReader(data), newOptions([]Option{WithFilename(name)}))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// memFile is an fs.File whose content is in memory
+type memFile struct {
+	*bytes.Reader
+	info fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memFile) Close() error               { return nil }
+
+// sizedInfo is a FileInfo whose size is another
+type sizedInfo struct {
+	fs.FileInfo
+	size int64
+}
+
+func (i sizedInfo) Size() int64 { return i.size }
+
+// previewSize returns the size of the preview to read from r. When r tells its size through
+// Stat, as *os.File and fs.File do, a regular file smaller than the preview gets a preview just
+// one byte bigger, enough to see its end
+func previewSize(r io.Reader, o *options) int {
+	n := o.preview()
+	st, ok := r.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return n
+	}
+	info, err := st.Stat()
+	if err != nil || !info.Mode().IsRegular() || info.Size() <= 0 {
+		// /proc and the like report no size
+		return n
+	}
+	if size := info.Size(); size < int64(n) {
+		return int(size) + 1
+	}
+	return n
+}
+
+// position returns the offset of r if it is an io.Seeker, -1 otherwise
+func position(r io.Reader) int64 {
+	if s, ok := r.(io.Seeker); ok {
+		if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+			return pos
+		}
+	}
+	return -1
+}
+
+// rewind seeks r back to start, the position returned by position, so that the preview
+// is read again from r instead of being kept in memory. It tells whether it could
+func rewind(r io.Reader, start int64) bool {
+	if start < 0 {
+		return false
+	}
+	_, err := r.(io.Seeker).Seek(start, io.SeekStart)
+	return err == nil
+}
@@ -0,0 +1,183 @@
+package txtopener
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOpenFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"latin.txt":     {Data: []byte("caf\xe9")},
+		"sub/utf16.txt": {Data: append(utf16lebom, "a\x00\xf1\x00o\x00"...)},
+	}
+
+	var tests = []struct {
+		name     string
+		expected string
+	}{
+		{"latin.txt", "café"},
+		{"sub/utf16.txt", "año"},
+	}
+	for i, tt := range tests {
+		rc, err := OpenFS(fsys, tt.name)
+		if err != nil {
+			t.Errorf("%d. error en OpenFS: %v", i, err)
+			continue
+		}
+		got, err := ioutil.ReadAll(rc)
+		if err != nil || string(got) != tt.expected {
+			t.Errorf("%d. %s -> got: %q, %v - expected: %q", i, tt.name, got, err, tt.expected)
+		}
+		if err := rc.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+	}
+
+	if _, err := OpenFS(fsys, "missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("got: %v - expected: %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestTranscodeFS(t *testing.T) {
+	fsys := TranscodeFS(fstest.MapFS{
+		"index.html":    {Data: []byte(`<meta charset="windows-1251"><p>` + "\xcf\xf0\xe8\xe2\xe5\xf2")},
+		"sub/latin.txt": {Data: []byte("caf\xe9")},
+		"sub/utf16.txt": {Data: append(utf16bebom, "\x00a\x00\xf1\x00o"...)},
+		"logo.png":      {Data: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\xe9")},
+		"sub/art.nfo":   {Data: []byte("\xdb\xdb\xb0 r\x82sum\x82")},
+	})
+
+	expected := map[string]string{
+		"index.html":    `<meta charset="windows-1251"><p>Привет`,
+		"sub/latin.txt": "café",
+		"sub/utf16.txt": "año",
+		"logo.png":      "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\xe9",
+		"sub/art.nfo":   "██░ résumé",
+	}
+	for name, want := range expected {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil || string(got) != want {
+			t.Errorf("%s -> got: %q, %v - expected: %q", name, got, err, want)
+		}
+		info, err := fs.Stat(fsys, name)
+		if err != nil || info.Size() != int64(len(want)) {
+			t.Errorf("%s -> size: %v, %v - expected: %d", name, info.Size(), err, len(want))
+		}
+	}
+
+	if err := fstest.TestFS(fsys, "index.html", "sub/latin.txt", "sub/utf16.txt", "logo.png", "sub/art.nfo"); err != nil {
+		t.Errorf("error en fstest.TestFS: %v", err)
+	}
+}
+
+func TestWalkText(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":         {Data: []byte("caf\xe9")},
+		"sub/b.txt":     {Data: append(utf16lebom, "a\x00\xf1\x00o\x00"...)},
+		"sub/c.txt":     {Data: []byte(string(utf8bom) + "ol\xc3\xa9")},
+		"skip/d.txt":    {Data: []byte("skipped")},
+		"sub/empty.txt": {Data: nil},
+	}
+
+	type walked struct {
+		content string
+		rep     Report
+	}
+	got := map[string]walked{}
+	err := WalkText(fsys, ".", func(name string, r io.Reader, rep Report) error {
+		if name == "skip/d.txt" {
+			return fs.SkipDir
+		}
+		content, err := ioutil.ReadAll(r)
+		got[name] = walked{string(content), rep}
+		return err
+	})
+	if err != nil {
+		t.Errorf("error en WalkText: %v", err)
+	}
+
+	expected := map[string]walked{
+		"a.txt":         {"café", Report{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+		"sub/b.txt":     {"año", Report{Encoding: "utf-16le", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		"sub/c.txt":     {"olé", Report{Encoding: "utf-8", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		"sub/empty.txt": {"", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got: %+v - expected: %+v", got, expected)
+	}
+
+	failed := errors.New("failed")
+	if err := WalkText(fsys, "sub", func(string, io.Reader, Report) error { return failed }); err != failed {
+		t.Errorf("got: %v - expected: %v", err, failed)
+	}
+}
+
+// readCounter counts the bytes read from a reader
+type readCounter struct {
+	fs.File
+	read int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	n, err := c.File.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestFileInputs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"small.txt": {Data: []byte("caf\xe9")},
+		"big.txt":   {Data: []byte(strings.Repeat("caf\xe9 ", 5000))},
+		"empty.txt": {Data: nil},
+	}
+
+	var tests = []struct {
+		name    string
+		preview int
+	}{
+		{"small.txt", 5},
+		{"big.txt", 10240},
+		{"empty.txt", 10240},
+	}
+	for i, tt := range tests {
+		f, err := fsys.Open(tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := previewSize(f, newOptions(nil)); got != tt.preview {
+			t.Errorf("%d. %s -> preview: %d - expected: %d", i, tt.name, got, tt.preview)
+		}
+		f.Close()
+	}
+	if got := previewSize(strings.NewReader("caf\xe9"), newOptions(nil)); got != 10240 {
+		t.Errorf("preview of a reader without Stat -> got: %d - expected: 10240", got)
+	}
+
+	// a file that can seek is read again from the start instead of keeping the preview
+	f, err := fsys.Open("big.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c := &readCounter{File: f}
+	r, err := NewReaderE(struct {
+		io.Reader
+		io.Seeker
+	}{c, f.(io.Seeker)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil || string(got) != strings.Repeat("café ", 5000) {
+		t.Errorf("got %d bytes, %v - expected %d", len(got), err, len(strings.Repeat("café ", 5000)))
+	}
+	if expected := 10240 + 25000; c.read != expected {
+		t.Errorf("read: %d bytes - expected: %d", c.read, expected)
+	}
+}
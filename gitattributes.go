@@ -0,0 +1,169 @@
+package txtopener
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// gitAttributesFile is the name of the files holding the attributes of Git paths
+const gitAttributesFile = ".gitattributes"
+
+// gitAttributes reads and caches the working-tree-encoding attributes of the .gitattributes files
+// of the directories, safe for concurrent use
+type gitAttributes struct {
+	mu    sync.Mutex
+	files map[string][]gitRule
+}
+
+// gitRule is a line of a .gitattributes file that sets or unsets working-tree-encoding.
+// An empty encoding unsets it
+type gitRule struct {
+	glob     *regexp.Regexp
+	encoding string
+}
+
+// workingTreeEncoding returns the working-tree-encoding of the file name, empty if it has none,
+// looking for the .gitattributes files from its directory up to the root of its repository.
+// The closer files, and the later lines of each file, win
+func (g *gitAttributes) workingTreeEncoding(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+	for dir := filepath.Dir(abs); ; {
+		rules, err := g.load(dir)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(dir, abs)
+		rel = filepath.ToSlash(rel)
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].glob.MatchString(rel) {
+				return rules[i].encoding, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir || isGitRoot(dir) {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// isGitRoot tells whether dir is the top of a working tree
+func isGitRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// load returns the rules of the .gitattributes file of dir
+func (g *gitAttributes) load(dir string) ([]gitRule, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if rules, ok := g.files[dir]; ok {
+		return rules, nil
+	}
+	if g.files == nil {
+		g.files = map[string][]gitRule{}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, gitAttributesFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	rules := parseGitAttributes(data)
+	g.files[dir] = rules
+	return rules, nil
+}
+
+// parseGitAttributes returns the rules of the lines of data that mention working-tree-encoding.
+// Quoted patterns and macros aren't supported
+func parseGitAttributes(data []byte) []gitRule {
+	var rules []gitRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		glob, err := gitGlob(fields[0])
+		if err != nil {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			switch {
+			case strings.HasPrefix(attr, "working-tree-encoding="):
+				rules = append(rules, gitRule{glob, strings.TrimPrefix(attr, "working-tree-encoding=")})
+			case attr == "-working-tree-encoding" || attr == "!working-tree-encoding":
+				rules = append(rules, gitRule{glob, ""})
+			}
+		}
+	}
+	return rules
+}
+
+// gitGlob compiles a .gitattributes pattern into a regular expression matching the slash-separated
+// paths relative to the directory of the file. Patterns without a slash match the base names
+func gitGlob(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			j := strings.IndexByte(pattern[i:], ']')
+			if j < 0 {
+				re.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += j
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.Compile(re.String())
+}
+
+// gitEncoding returns the label and the BOM of a working-tree-encoding. As Git does, UTF-16
+// without endianness and the -BOM names carry a BOM
+func gitEncoding(name string) (label string, bom bool) {
+	label = strings.ToLower(name)
+	if strings.HasSuffix(label, "-bom") {
+		return strings.TrimSuffix(label, "-bom"), true
+	}
+	if label == "utf-16" || label == "utf16" {
+		return "utf-16le", true
+	}
+	return label, false
+}
@@ -0,0 +1,80 @@
+package txtopener
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitGlob(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"*.ps1", "scripts/a.ps1", true},
+		{"/*.ps1", "scripts/a.ps1", false},
+		{"scripts/*.ps1", "scripts/a.ps1", true},
+		{"scripts/*.ps1", "scripts/sub/a.ps1", false},
+		{"scripts/**/*.ps1", "scripts/sub/a.ps1", true},
+		{"**/win/*", "a/b/win/c.txt", true},
+		{"doc/**", "doc/x/y.txt", true},
+		{"file[0-9].txt", "file7.txt", true},
+	}
+
+	for i, tt := range tests {
+		re, err := gitGlob(tt.pattern)
+		if err != nil {
+			t.Errorf("%d. error en gitGlob: %v", i, err)
+			continue
+		}
+		if got := re.MatchString(tt.path); got != tt.matches {
+			t.Errorf("%d. feeded: %q, %q -> got: %v - expected: %v", i, tt.pattern, tt.path, got, tt.matches)
+		}
+	}
+}
+
+func TestConvertTreeGitAttributes(t *testing.T) {
+	files := map[string]string{
+		".git/HEAD":      "ref: refs/heads/master\n",
+		".gitattributes": "*.ps1 text working-tree-encoding=UTF-16\n*.txt working-tree-encoding=windows-1252\nkeep.txt -working-tree-encoding\n",
+		"a.ps1":          "caf\xe9",
+		"b.txt":          "caf\xe9",
+		"keep.txt":       "caf\xe9",
+		"c.md":           "caf\xe9",
+	}
+
+	var tests = []struct {
+		policy   GitAttributesPolicy
+		expected map[string]string
+	}{
+		{GitAttributesSkip, map[string]string{"a.ps1": "caf\xe9", "b.txt": "caf\xe9", "keep.txt": "café", "c.md": "café"}},
+		{GitAttributesFollow, map[string]string{"a.ps1": string(utf16lebom) + "c\x00a\x00f\x00\xe9\x00", "b.txt": "caf\xe9", "keep.txt": "café", "c.md": "café"}},
+	}
+
+	for i, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range files {
+			name = filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(name, []byte(content), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := ConvertTree(dir, ConvertOptions{Exclude: []string{".git"}, GitAttributes: tt.policy}); err != nil {
+			t.Errorf("%d. error en ConvertTree: %v", i, err)
+		}
+		for name, want := range tt.expected {
+			got, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != want {
+				t.Errorf("%d. %s -> got: %q - expected: %q", i, name, got, want)
+			}
+		}
+	}
+}
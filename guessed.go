@@ -0,0 +1,31 @@
+package txtopener
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrGuessedEncoding is returned by the readers created WithRejectGuessed when the encoding of
+// the input could only be guessed
+var ErrGuessedEncoding = errors.New("txtopener: guessed encoding")
+
+// WithRejectGuessed makes the reader fail with ErrGuessedEncoding instead of decoding input
+// whose Evidence is Guessed, so that pipelines can quarantine it rather than corrupt it.
+// A preview of plain ASCII taken as the fallback is let through, it reads the same in any of them
+func WithRejectGuessed() Option {
+	return func(o *options) {
+		o.rejectGuessed = true
+	}
+}
+
+// checkGuessed returns an error if guesses are rejected and the encoding named name was
+// decided by src, a guess, for preview
+func checkGuessed(preview []byte, name string, src Evidence, o *options) error {
+	if !o.rejectGuessed || !src.Guessed() {
+		return nil
+	}
+	if src == EvidenceFallback && asciiPrefix(preview) == len(preview) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrGuessedEncoding, name)
+}
@@ -0,0 +1,48 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestRejectGuessed(t *testing.T) {
+	greek, err := charmap.ISO8859_7.NewEncoder().String(greekSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+		err      error
+	}{
+		{"plain ascii", nil, "plain ascii", nil},
+		{"café", nil, "café", nil},
+		{string(utf16lebom) + "\xe9\x00", nil, "é", nil},
+		{"caf\xe9", []Option{WithContentType("text/plain; charset=windows-1252")}, "café", nil},
+		{`<meta charset="windows-1252">caf` + "\xe9", nil, `<meta charset="windows-1252">café`, nil},
+		{"caf\xe9", nil, "", ErrGuessedEncoding},
+		{"caf\xe9", []Option{WithFallback("windows-1252")}, "", ErrGuessedEncoding},
+		{greek, nil, "", ErrGuessedEncoding},
+	}
+
+	for i, tt := range tests {
+		var got []byte
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), append(tt.opts, WithRejectGuessed())...)
+		if err == nil {
+			got, err = ioutil.ReadAll(r)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. feeded: %q -> got error: %v - expected: %v", i, tt.feed, err, tt.err)
+			continue
+		}
+		if tt.err == nil && string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
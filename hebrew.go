@@ -0,0 +1,152 @@
+package txtopener
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// isVisualHebrew tells whether the ISO-8859-8 content stores Hebrew in visual order, that is,
+// each line reversed as it is displayed. In logical order the final forms of the letters
+// (ך ם ן ף ץ) end the words; in visual order they show up at their beginning
+func isVisualHebrew(content []byte) bool {
+	hebrew := func(i int) bool {
+		return i >= 0 && i < len(content) && content[i] >= 0xe0 && content[i] <= 0xfa
+	}
+	starts, ends := 0, 0
+	for i, b := range content {
+		switch b {
+		case 0xea, 0xed, 0xef, 0xf3, 0xf5:
+			if hebrew(i-1) && !hebrew(i+1) {
+				ends++
+			} else if !hebrew(i-1) && hebrew(i+1) {
+				starts++
+			}
+		}
+	}
+	return starts > ends
+}
+
+// WithVisualToLogical reorders visual-order Hebrew (the iso-8859-8 encoding, as opposed to the
+// logical iso-8859-8-i) to logical order while decoding, line by line
+func WithVisualToLogical() Option {
+	return func(o *options) {
+		o.visualToLogical = true
+	}
+}
+
+// maxVisualLine is the longest line reordered as a whole, longer ones are reordered in pieces
+const maxVisualLine = 2048
+
+// visualToLogical is a transformer of UTF-8 text that turns visual-order lines into logical ones
+type visualToLogical struct {
+	transform.NopResetter
+}
+
+func (visualToLogical) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		line := src[nSrc:]
+		eol := bytes.IndexByte(line, '\n')
+		switch {
+		case eol >= 0:
+			line = line[:eol]
+		case len(line) > maxVisualLine:
+			line = line[:maxVisualLine]
+			for len(line) > 0 && !utf8.RuneStart(line[len(line)-1]) {
+				line = line[:len(line)-1]
+			}
+			line = line[:len(line)-1]
+		case !atEOF:
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+
+		n := len(line)
+		if eol >= 0 {
+			n++
+		}
+		if nDst+n > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], reorderVisual(line))
+		if eol >= 0 {
+			dst[nDst] = '\n'
+			nDst++
+		}
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// reorderVisual turns a visual-order line into logical order: the whole line is reversed,
+// with mirrored brackets, and then the left-to-right runs (Latin words, numbers) are put back
+// in their reading order
+func reorderVisual(line []byte) []byte {
+	cr := bytes.HasSuffix(line, []byte("\r"))
+	if cr {
+		line = line[:len(line)-1]
+	}
+	rs := []rune(string(line))
+	reverse(rs)
+	for i, r := range rs {
+		rs[i] = mirror(r)
+	}
+
+	for i := 0; i < len(rs); {
+		if !isLTR(rs[i]) {
+			i++
+			continue
+		}
+		j, end := i, i
+		for ; j < len(rs) && !unicode.Is(unicode.Hebrew, rs[j]); j++ {
+			if isLTR(rs[j]) {
+				end = j + 1
+			}
+		}
+		run := rs[i:end]
+		reverse(run)
+		for k, r := range run {
+			run[k] = mirror(r)
+		}
+		i = end
+	}
+
+	out := []byte(string(rs))
+	if cr {
+		out = append(out, '\r')
+	}
+	return out
+}
+
+func isLTR(r rune) bool {
+	return unicode.IsDigit(r) || unicode.IsLetter(r) && !unicode.Is(unicode.Hebrew, r)
+}
+
+func reverse(rs []rune) {
+	for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+		rs[i], rs[j] = rs[j], rs[i]
+	}
+}
+
+func mirror(r rune) rune {
+	switch r {
+	case '(':
+		return ')'
+	case ')':
+		return '('
+	case '[':
+		return ']'
+	case ']':
+		return '['
+	case '{':
+		return '}'
+	case '}':
+		return '{'
+	case '<':
+		return '>'
+	case '>':
+		return '<'
+	}
+	return r
+}
@@ -0,0 +1,111 @@
+//go:build iconv
+
+package txtopener
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+// iconvNames maps the names of the encodings whose label iconv doesn't know to the iconv ones
+var iconvNames = map[string]string{
+	"ISO 8859-1":     "ISO-8859-1",
+	"iso-8859-8-i":   "ISO-8859-8",
+	"x-mac-cyrillic": "MAC-CYRILLIC",
+}
+
+// Divergence describes a file that txtopener and iconv convert to UTF-8 differently
+type Divergence struct {
+	// Path is the name of the file
+	Path string
+	// Encoding is the name of the encoding txtopener detected
+	Encoding string
+	// Offset is the position, in bytes of UTF-8, of the first difference
+	Offset int
+	// Got holds up to 16 bytes of the output of txtopener starting at Offset
+	Got []byte
+	// Iconv holds up to 16 bytes of the output of iconv starting at Offset
+	Iconv []byte
+	// Err is the error of iconv, when it failed to convert the file
+	Err error
+}
+
+func (d Divergence) String() string {
+	if d.Err != nil {
+		return fmt.Sprintf("%s (%s): iconv: %v", d.Path, d.Encoding, d.Err)
+	}
+	return fmt.Sprintf("%s (%s): at byte %d got %q, iconv %q", d.Path, d.Encoding, d.Offset, d.Got, d.Iconv)
+}
+
+// VerifyIconv converts every file of paths to UTF-8 twice, with NewReader and with the iconv
+// command of the system told the encoding detected, and returns the files converted differently.
+// It is built only with the iconv build tag, for the certification of mass migrations
+func VerifyIconv(paths []string) ([]Divergence, error) {
+	var divergences []Divergence
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return divergences, err
+		}
+		got, err := ioutil.ReadAll(NewReader(bytes.NewReader(data)))
+		if err != nil {
+			return divergences, err
+		}
+
+		_, name, _ := determineEncoding(data, newOptions(nil))
+		want, err := iconv(data, name)
+		if err != nil {
+			divergences = append(divergences, Divergence{Path: path, Encoding: name, Err: err})
+			continue
+		}
+
+		m := len(got)
+		if len(want) < m {
+			m = len(want)
+		}
+		i := mismatch(got[:m], want[:m])
+		if i < 0 && len(got) != len(want) {
+			i = m
+		}
+		if i >= 0 {
+			divergences = append(divergences, Divergence{
+				Path:     path,
+				Encoding: name,
+				Offset:   i,
+				Got:      clip(got[i:], lossContext),
+				Iconv:    clip(want[i:], lossContext),
+			})
+		}
+	}
+	return divergences, nil
+}
+
+// iconv converts data from the encoding name to UTF-8 with the iconv command. The BOMs are
+// removed, as NewReader does
+func iconv(data []byte, name string) ([]byte, error) {
+	if n, ok := iconvNames[name]; ok {
+		name = n
+	}
+	for _, b := range boms {
+		if bytes.HasPrefix(data, b.bom) {
+			data = data[len(b.bom):]
+			break
+		}
+	}
+
+	cmd := exec.Command("iconv", "-f", name, "-t", "UTF-8")
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,51 @@
+//go:build iconv
+
+package txtopener
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyIconv(t *testing.T) {
+	if _, err := exec.LookPath("iconv"); err != nil {
+		t.Skip("no iconv command")
+	}
+
+	var tests = []struct {
+		content  string
+		diverges bool
+	}{
+		{"\xef\xbb\xbfaño", false},
+		{"\xff\xfea\x00\xf1\x00o\x00", false},
+		{"<meta charset=\"shift_jis\">\x83e\x83X\x83g", false},
+		{"<meta charset=\"koi8-r\">\xf0\xd2\xc9\xd7\xc5\xd4", false},
+		{"<meta charset=\"windows-1252\">caf\xe9 \x81", true},
+	}
+
+	dir := t.TempDir()
+	var paths []string
+	for i, tt := range tests {
+		path := filepath.Join(dir, string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	divergences, err := VerifyIconv(paths)
+	if err != nil {
+		t.Fatalf("error en VerifyIconv: %v", err)
+	}
+	diverged := map[string]bool{}
+	for _, d := range divergences {
+		diverged[d.Path] = true
+	}
+	for i, tt := range tests {
+		if diverged[paths[i]] != tt.diverges {
+			t.Errorf("%d. feeded: %q -> diverges: %v - expected: %v (%v)", i, tt.content, !tt.diverges, tt.diverges, divergences)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package txtopener
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// WithInnerBOMStrip makes the reader drop the BOMs found past the start of the input, as files
+// put together by naive concatenation carry at the start of every piece, instead of passing them
+// on as zero width no-break spaces. The BOM at the start is handled by WithBOMPolicy
+func WithInnerBOMStrip() Option {
+	return func(o *options) {
+		o.innerBOMs = true
+	}
+}
+
+// innerBOMStripper is a transformer of UTF-8 text that drops every U+FEFF but the first
+// character
+type innerBOMStripper struct {
+	// started tells that the first character was seen
+	started bool
+}
+
+func (s *innerBOMStripper) Reset() {
+	s.started = false
+}
+
+func (s *innerBOMStripper) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == '\ufeff' && s.started {
+			nSrc += size
+			continue
+		}
+		if len(dst)-nDst < size {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+		s.started = true
+	}
+	return nDst, nSrc, nil
+}
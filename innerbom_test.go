@@ -0,0 +1,66 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/transform"
+)
+
+func TestWithInnerBOMStrip(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+	}{
+		{"\xef\xbb\xbfuno\n\xef\xbb\xbfdos\n", []Option{WithInnerBOMStrip()}, "uno\ndos\n"},
+		{"\xef\xbb\xbfuno\n\xef\xbb\xbfdos\n", nil, "uno\n\ufeffdos\n"},
+		{"\xef\xbb\xbfuno\n\xef\xbb\xbfdos\n", []Option{WithInnerBOMStrip(), WithBOMPolicy(BOMPreserve)}, "\ufeffuno\ndos\n"},
+		{"\xef\xbb\xbfuno\n\xef\xbb\xbfdos\n", []Option{WithInnerBOMStrip(), WithBOMPolicy(BOMAdd)}, "\ufeffuno\ndos\n"},
+		{"uno\n\xef\xbb\xbfdos\n\xef\xbb\xbftres", []Option{WithInnerBOMStrip()}, "uno\ndos\ntres"},
+		{"\xff\xfeu\x00n\x00o\x00\n\x00\xff\xfed\x00o\x00s\x00", []Option{WithInnerBOMStrip()}, "uno\ndos"},
+		{"\xfe\xff\x00u\x00n\x00o\xfe\xff\x00d\x00o\x00s", []Option{WithInnerBOMStrip()}, "unodos"},
+		{"año\n\xef\xbb\xbfaño", []Option{WithInnerBOMStrip(), WithStrict()}, "año\naño"},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestInnerBOMStripper(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{"\ufeffuno\ufeffdos\ufeff", "\ufeffunodos"},
+		{"uno\ufeff\ufeffdos", "unodos"},
+		{"\ufeff\ufeff", "\ufeff"},
+		{"ñ\ufeffñ", "ññ"},
+		{"", ""},
+	}
+
+	for i, tt := range tests {
+		got, _, err := transform.String(&innerBOMStripper{}, tt.feed)
+		if err != nil {
+			t.Errorf("%d. error en Transform: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		r := transform.NewReader(iotest.OneByteReader(strings.NewReader(tt.feed)), &innerBOMStripper{})
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. byte by byte, feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package txtopener
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// Legacy single-byte encodings missing from the x/text and WHATWG sets. All of them match
+// ASCII in their lower half
+var (
+	armscii8 = highCharmap("armscii-8",
+		"\u0080\u0081\u0082\u0083\u0084\u0085\u0086\u0087\u0088\u0089\u008a\u008b\u008c\u008d\u008e\u008f"+
+			"\u0090\u0091\u0092\u0093\u0094\u0095\u0096\u0097\u0098\u0099\u009a\u009b\u009c\u009d\u009e\u009f"+
+			"\u00a0\ufffdև։)(»«—.՝,-֊…՜"+
+			"՛՞ԱաԲբԳգԴդԵեԶզԷէ"+
+			"ԸըԹթԺժԻիԼլԽխԾծԿկ"+
+			"ՀհՁձՂղՃճՄմՅյՆնՇշ"+
+			"ՈոՉչՊպՋջՌռՍսՎվՏտ"+
+			"ՐրՑցՒւՓփՔքՕօՖֆ՚\ufffd")
+
+	georgianAcademy = highCharmap("georgian-academy",
+		"\u0080\u0081‚ƒ„…†‡ˆ‰Š‹Œ\u008d\u008e\u008f"+
+			"\u0090‘’“”•–—˜™š›œ\u009d\u009eŸ"+
+			"\u00a0¡¢£¤¥¦§¨©ª«¬\u00ad®¯"+
+			"°±²³´µ¶·¸¹º»¼½¾¿"+
+			"აბგდევზთიკლმნოპჟ"+
+			"რსტუფქღყშჩცძწჭხჯ"+
+			"ჰჱჲჳჴჵჶçèéêëìíîï"+
+			"ðñòóôõö÷øùúûüýþÿ")
+
+	georgianPS = highCharmap("georgian-ps",
+		"\u0080\u0081‚ƒ„…†‡ˆ‰Š‹Œ\u008d\u008e\u008f"+
+			"\u0090‘’“”•–—˜™š›œ\u009d\u009eŸ"+
+			"\u00a0¡¢£¤¥¦§¨©ª«¬\u00ad®¯"+
+			"°±²³´µ¶·¸¹º»¼½¾¿"+
+			"აბგდევზჱთიკლმნჲო"+
+			"პჟრსტჳუფქღყშჩცძწ"+
+			"ჭხჴჯჰჵæçèéêëìíîï"+
+			"ðñòóôõö÷øùúûüýþÿ")
+
+	viscii = func() *Charmap {
+		table := highTable("viscii",
+			"ẠẮẰẶẤẦẨẬẼẸẾỀỂỄỆỐ"+
+				"ỒỔỖỘỢỚỜỞỊỎỌỈỦŨỤỲ"+
+				"Õắằặấầẩậẽẹếềểễệố"+
+				"ồổỗỠƠộờởịỰỨỪỬơớƯ"+
+				"ÀÁÂÃẢĂẳẵÈÉÊẺÌÍĨỳ"+
+				"ĐứÒÓÔạỷừửÙÚỹỵÝỡư"+
+				"àáâãảăữẫèéêẻìíĩỉ"+
+				"đựòóôõỏọụùúũủýợỮ")
+		// VISCII takes six of the C0 control codes for the capital letters that don't fit above
+		for b, s := range map[byte]string{0x02: "Ẳ", 0x05: "Ẵ", 0x06: "Ẫ", 0x14: "Ỷ", 0x19: "Ỹ", 0x1e: "Ỵ"} {
+			table[b] = s
+		}
+		return NewCharmap("viscii", table)
+	}()
+)
+
+// legacyEncoding is an encoding of legacyLabels, which knows its canonical name
+type legacyEncoding interface {
+	encoding.Encoding
+	String() string
+}
+
+// named gives a canonical name to an encoding of x/text
+type named struct {
+	encoding.Encoding
+	name string
+}
+
+func (n named) String() string {
+	return n.name
+}
+
+// legacyLabels maps the labels of the legacy encodings, in lowercase, to the encodings.
+// They are resolved before the WHATWG ones
+var legacyLabels = withCodePages(map[string]legacyEncoding{
+	"armscii-8":          armscii8,
+	"armscii8":           armscii8,
+	"armscii":            armscii8,
+	"hy-armscii-8":       armscii8,
+	"georgian-academy":   georgianAcademy,
+	"x-georgian-academy": georgianAcademy,
+	"georgian-ps":        georgianPS,
+	"x-georgian-ps":      georgianPS,
+	"viscii":             viscii,
+	"csviscii":           viscii,
+	"viscii1.1-1":        viscii,
+	"tscii":              tscii{tsciiCharmap},
+	"x-tscii":            tscii{tsciiCharmap},
+	// UTF-32 isn't in the WHATWG set, which dropped it from the web
+	"utf-32le": utf32le,
+	"utf-32be": utf32be,
+	"utf-32":   named{utf32.UTF32(utf32.BigEndian, utf32.UseBOM), "utf-32"},
+	// neither is UTF-7, which browsers refuse for its use in XSS attacks
+	"utf-7":             utf7{},
+	"unicode-1-1-utf-7": utf7{},
+	"csunicode11utf7":   utf7{},
+	// CP949, the Unified Hangul Code, is what WHATWG decodes as EUC-KR
+	"cp949": euckr,
+	"uhc":   euckr,
+	"ms949": euckr,
+})
+
+var (
+	euckr   = named{korean.EUCKR, "euc-kr"}
+	utf32le = named{utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM), "utf-32le"}
+	utf32be = named{utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM), "utf-32be"}
+)
+
+// withCodePages adds to labels the DOS code pages of x/text, which the WHATWG set lacks,
+// as ibmNNN, cpNNN and NNN
+func withCodePages(labels map[string]legacyEncoding) map[string]legacyEncoding {
+	for num, e := range map[string]encoding.Encoding{
+		"437": charmap.CodePage437,
+		"850": charmap.CodePage850,
+		"852": charmap.CodePage852,
+		"855": charmap.CodePage855,
+		"858": charmap.CodePage858,
+		"860": charmap.CodePage860,
+		"862": charmap.CodePage862,
+		"863": charmap.CodePage863,
+		"865": charmap.CodePage865,
+	} {
+		cp := named{e, "ibm" + num}
+		labels["ibm"+num], labels["cp"+num], labels[num] = cp, cp, cp
+	}
+	return labels
+}
+
+// registered holds the labels of the encodings registered with RegisterEncoding, in lowercase
+var registered struct {
+	sync.RWMutex
+	labels map[string]legacyEncoding
+}
+
+// RegisterEncoding makes e known to the package as name and as each of aliases, so that the
+// detection, the writers and the constructors taking labels resolve them: company-internal
+// code pages, variants of CP437 and the like. Labels are matched regardless of case and win over
+// those of the package; registering nil for a name forgets name and aliases. It is safe for
+// concurrent use
+func RegisterEncoding(name string, e encoding.Encoding, aliases ...string) {
+	registered.Lock()
+	defer registered.Unlock()
+	if registered.labels == nil {
+		registered.labels = make(map[string]legacyEncoding)
+	}
+	for _, label := range append([]string{name}, aliases...) {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if e == nil {
+			delete(registered.labels, label)
+		} else {
+			registered.labels[label] = named{e, name}
+		}
+	}
+}
+
+// lookupLegacy resolves label against the registered encodings and legacyLabels
+func lookupLegacy(label string) (legacyEncoding, bool) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	registered.RLock()
+	c, ok := registered.labels[label]
+	registered.RUnlock()
+	if ok {
+		return c, true
+	}
+	c, ok = legacyLabels[label]
+	return c, ok
+}
+
+// highCharmap builds a Charmap that matches ASCII below 0x80 and maps the bytes from 0x80 on
+// to the runes of high, in order. U+FFFD marks the undefined bytes
+func highCharmap(name, high string) *Charmap {
+	return NewCharmap(name, highTable(name, high))
+}
+
+// highTable returns the table of highCharmap
+func highTable(name, high string) map[byte]string {
+	table := make(map[byte]string)
+	for b := 0; b < 0x80; b++ {
+		table[byte(b)] = string(rune(b))
+	}
+	b := 0x80
+	for _, r := range high {
+		if r != utf8.RuneError {
+			table[byte(b)] = string(r)
+		}
+		b++
+	}
+	if b != 0x100 {
+		panic("txtopener: the table of " + name + " doesn't cover 128 bytes")
+	}
+	return table
+}
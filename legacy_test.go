@@ -0,0 +1,85 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	armenianSample   = "Բոլոր մարդիկ ծնվում են ազատ ու հավասար իրենց արժանապատվությամբ ու իրավունքներով։"
+	georgianSample   = "ყველა ადამიანი იბადება თავისუფალი და თანასწორი თავისი ღირსებითა და უფლებებით."
+	vietnameseSample = "Tất cả mọi người sinh ra đều được tự do và bình đẳng về nhân phẩm và quyền. Ỷ Lan"
+	tamilSample      = "மனிதப் பிறவியினர் சகலரும் சுதந்திரமாகவே பிறக்கின்றனர்; அவர்கள் ஒருவருடனொருவர் சகோதர உணர்வுப் பாங்கில் நடந்துகொள்ளல் வேண்டும். கௌரவம்"
+)
+
+func TestLegacyEncodings(t *testing.T) {
+	var tests = []struct {
+		text  string
+		label string
+		meta  bool
+	}{
+		{armenianSample, "armscii-8", false},
+		{armenianSample, "ARMSCII8", true},
+		{georgianSample, "georgian-academy", false},
+		{georgianSample, "georgian-ps", false},
+		{georgianSample, "x-georgian-ps", true},
+		{vietnameseSample, "viscii", false},
+		{tamilSample, "tscii", false},
+		{tamilSample, "TSCII", true},
+		{koreanSample, "cp949", true},
+		{"똠방각하", "UHC", true},
+	}
+
+	for i, tt := range tests {
+		e, _, err := lookup(tt.label)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		feed, err := e.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		if tt.meta {
+			feed = `<meta charset="` + tt.label + `">` + feed
+		}
+		got, err := ioutil.ReadAll(NewReader(strings.NewReader(feed)))
+		if err != nil {
+			t.Errorf("error en ReadAll: %v", err)
+		}
+		if strings.TrimPrefix(string(got), `<meta charset="`+tt.label+`">`) != tt.text {
+			t.Errorf("%d. %s -> got: %s - expected: %s", i, tt.label, got, tt.text)
+		}
+	}
+}
+
+func TestRegisterEncoding(t *testing.T) {
+	RegisterEncoding("x-acme-437", charmap.CodePage437, "acme", "CP-ACME")
+	defer RegisterEncoding("x-acme-437", nil, "acme", "CP-ACME")
+
+	var tests = []struct {
+		feed        string
+		contentType string
+		name        string
+		expected    string
+	}{
+		{"\x82t\x82", "text/plain; charset=x-acme-437", "x-acme-437", "été"},
+		{"\x82t\x82", "text/plain; charset=cp-acme", "x-acme-437", "été"},
+		{`<meta charset="ACME">` + "\x82t\x82", "", "x-acme-437", `<meta charset="ACME">été`},
+	}
+
+	for i, tt := range tests {
+		_, name, _ := DetectEncoding([]byte(tt.feed), tt.contentType)
+		got, err := ioutil.ReadAll(NewReaderContentType(strings.NewReader(tt.feed), tt.contentType))
+		if err != nil || name != tt.name || string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %s, %q, %v - expected: %s, %q", i, tt.feed, name, got, err, tt.name, tt.expected)
+		}
+	}
+
+	RegisterEncoding("x-acme-437", nil, "acme", "CP-ACME")
+	if _, _, err := lookup("acme"); err == nil {
+		t.Errorf("acme still known after being forgotten")
+	}
+}
@@ -0,0 +1,203 @@
+package txtopener
+
+import (
+	"bytes"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// cjkLanguage describes the characters most used in a language written with several bytes per
+// character and the multi-byte charsets it is usually written with, most common first.
+// common lists its most frequent ideographs or syllables; for Japanese every kana counts as well
+type cjkLanguage struct {
+	code string
+	kana bool
+	// spaced tells a language that separates its words with spaces
+	spaced   bool
+	common   string
+	charsets []string
+}
+
+// cjkLanguages is the set of models of the multi-byte detector
+var cjkLanguages = []cjkLanguage{
+	{code: "ja", kana: true, common: "日本人一大年中出会時見国行事今上下生子学自分気前後言思手方者私何来", charsets: []string{"shift_jis", "euc-jp"}},
+	{code: "zh", common: "的一是不了在人有我他这个们中来上大为和国地到以说时要就出会可也你对生能而子那得于着下自之年过发后作里用道行所然家种事成方多经么去法学如都同现当没动面起看定天分还进好小部其些主样理心本前开但因只从想实日這個們來為國說時會對於著過發後裡種經麼學現當沒動還進樣開從實", charsets: []string{"gbk", "gb18030", "big5"}},
+	{code: "ko", spaced: true, common: "이다의는에가을하고지를한기서사로으도리자나대어정수있인일시해것아들적전부국만주게없그년상요우", charsets: []string{"euc-kr"}},
+}
+
+// cjkLevel1 is the range of the most used characters of each multi-byte charset, the first
+// level of its character set: few texts use a character outside of it, and text in another
+// charset seldom decodes to many of them. Only the pairs whose second byte is at least trail
+// and that decode to a character of script belong to the range. For EUC-KR it takes in the
+// syllables CP949 adds, the Unified Hangul Code, which WHATWG decodes as EUC-KR
+var cjkLevel1 = map[string]struct {
+	first, last uint16
+	trail       byte
+	script      *unicode.RangeTable
+}{
+	"shift_jis": {0x889f, 0x9872, 0x40, unicode.Han},
+	"euc-jp":    {0xb0a1, 0xcfd3, 0xa1, unicode.Han},
+	"gbk":       {0xb0a1, 0xd7f9, 0xa1, unicode.Han},
+	"gb18030":   {0xb0a1, 0xd7f9, 0xa1, unicode.Han},
+	"big5":      {0xa440, 0xc67e, 0x40, unicode.Han},
+	"euc-kr":    {0x8141, 0xc8fe, 0x41, unicode.Hangul},
+}
+
+// thresholds of the multi-byte detector
+const (
+	// cjkMinScore is how much the characters of content have to add up to for a claim
+	cjkMinScore = 2.0
+	// cjkMinRatio is the score, from 0 to 1, content has to reach for a claim
+	cjkMinRatio = 0.25
+	// cjkLevel1Weight is what a character of the first level that isn't common adds to the score
+	cjkLevel1Weight = 0.5
+)
+
+// cjkCandidate is a multi-byte charset together with the model of one language written with it
+type cjkCandidate struct {
+	lang   string
+	name   string
+	e      encoding.Encoding
+	kana   bool
+	spaced bool
+	common map[rune]bool
+	level1 map[rune]bool
+}
+
+var (
+	cjkOnce  sync.Once
+	cjkCands []*cjkCandidate
+)
+
+// cjkCandidates builds, once, the candidates of every language and charset pair
+func cjkCandidates() []*cjkCandidate {
+	cjkOnce.Do(func() {
+		for _, lang := range cjkLanguages {
+			common := make(map[rune]bool)
+			for _, r := range lang.common {
+				common[r] = true
+			}
+			for _, name := range lang.charsets {
+				e, name, err := lookup(name)
+				if err != nil {
+					continue
+				}
+				cjkCands = append(cjkCands, &cjkCandidate{lang: lang.code, name: name, e: e, kana: lang.kana,
+					spaced: lang.spaced, common: common, level1: level1Runes(e, name)})
+			}
+		}
+	})
+	return cjkCands
+}
+
+// level1Runes returns the characters the pairs of bytes of the first level of the charset name,
+// e, decode to
+func level1Runes(e encoding.Encoding, name string) map[rune]bool {
+	level1, ok := cjkLevel1[name]
+	if !ok {
+		return nil
+	}
+	runes := make(map[rune]bool)
+	d := e.NewDecoder()
+	for code := int(level1.first); code <= int(level1.last); code++ {
+		if byte(code) < level1.trail {
+			continue
+		}
+		s, err := d.Bytes([]byte{byte(code >> 8), byte(code)})
+		r, n := utf8.DecodeRune(s)
+		if err == nil && n == len(s) && unicode.Is(level1.script, r) {
+			runes[r] = true
+		}
+	}
+	return runes
+}
+
+// rate scores how well content, decoded with the candidate, fits its language: the share of its
+// characters beyond ASCII that are common in the language, the characters of the first level
+// that aren't counting for cjkLevel1Weight. Those glued to an ASCII letter, as the bytes of
+// Latin text decoded as pairs would be, don't count, nor those next to a space in the languages
+// that don't separate their words, as the words of a single-byte charset would be. It returns as well what the characters add
+// up to. Content that doesn't decode cleanly, but for a character cut at the end, rates -1
+func (c *cjkCandidate) rate(content []byte) (ratio, score float64) {
+	text, err := c.e.NewDecoder().Bytes(content)
+	if err != nil {
+		return -1, 0
+	}
+	var wide int
+	var prev rune
+	for len(text) > 0 {
+		r, n := utf8.DecodeRune(text)
+		text = text[n:]
+		next, _ := utf8.DecodeRune(text)
+		switch {
+		case r == utf8.RuneError && len(text) > 0:
+			return -1, 0
+		case r < utf8.RuneSelf:
+		case next < utf8.RuneSelf && isASCIILetter(byte(next)) || prev < utf8.RuneSelf && isASCIILetter(byte(prev)),
+			!c.spaced && (next == ' ' || prev == ' '):
+			wide++
+		case c.common[r] || c.kana && (unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r)) && r < 0xff00:
+			wide++
+			score++
+		case c.level1[r]:
+			wide++
+			score += cjkLevel1Weight
+		default:
+			wide++
+		}
+		prev = r
+	}
+	if wide == 0 {
+		return -1, 0
+	}
+	return score / float64(wide), score
+}
+
+// detectMBCS decodes content with the multi-byte charsets of the Chinese, Japanese and Korean
+// languages, after looking for the escape sequences of ISO-2022-JP, and returns the one that
+// decodes it cleanly into the most common characters, if enough of them. The charsets of the
+// language lang, if not empty, are claimed as soon as they decode content cleanly and win over
+// those of the other languages
+func detectMBCS(content []byte, lang string) (e encoding.Encoding, name string, confidence float64) {
+	if isISO2022JP(content) {
+		e, name, _ = lookup("iso-2022-jp")
+		return e, name, 1
+	}
+	var best *cjkCandidate
+	var ratio float64
+	for _, c := range cjkCandidates() {
+		r, score := c.rate(content)
+		hinted := c.lang == lang
+		switch {
+		case hinted && r < 0, !hinted && (r < cjkMinRatio || score < cjkMinScore):
+		case best == nil, hinted && best.lang != lang, hinted == (best.lang == lang) && r > ratio:
+			best, ratio = c, r
+		}
+	}
+	if best == nil {
+		return nil, "", 0
+	}
+	return best.e, best.name, ratio
+}
+
+// iso2022JPDesignations are the escape sequences ISO-2022-JP switches to JIS X 0208 with
+var iso2022JPDesignations = [][]byte{[]byte("\x1b$B"), []byte("\x1b$@")}
+
+// isISO2022JP tells whether content is written in ISO-2022-JP: 7-bit text that switches to
+// JIS X 0208 with escape sequences
+func isISO2022JP(content []byte) bool {
+	for _, c := range content {
+		if c >= 0x80 {
+			return false
+		}
+	}
+	for _, esc := range iso2022JPDesignations {
+		if bytes.Contains(content, esc) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// samples of text in several languages used to check the multi-byte detector
+var (
+	japaneseSample    = "すべての人間は、生まれながらにして自由であり、かつ、尊厳と権利とについて平等である。人間は、理性と良心とを授けられており、互いに同胞の精神をもって行動しなければならない。"
+	simplifiedSample  = "人人生而自由，在尊严和权利上一律平等。他们赋有理性和良心，并应以兄弟关系的精神相对待。我们的国家有很多人。"
+	traditionalSample = "人人生而自由，在尊嚴和權利上一律平等。他們賦有理性和良心，並應以兄弟關係的精神相對待。我們的國家有很多人。"
+	koreanSample      = "모든 인간은 태어날 때부터 자유로우며 그 존엄과 권리에 있어 동등하다. 인간은 천부적으로 이성과 양심을 부여받았으며 서로 형제애의 정신으로 행동하여야 한다."
+)
+
+func TestDetectMBCS(t *testing.T) {
+	var tests = []struct {
+		text string
+		enc  encoding.Encoding
+	}{
+		{japaneseSample, japanese.ShiftJIS},
+		{japaneseSample, japanese.EUCJP},
+		{japaneseSample, japanese.ISO2022JP},
+		{"東京 Tokyo", japanese.ISO2022JP},
+		{simplifiedSample, simplifiedchinese.GBK},
+		{simplifiedSample + "€㐀", simplifiedchinese.GB18030},
+		{traditionalSample, traditionalchinese.Big5},
+		{"北京欢迎你", simplifiedchinese.GBK},
+		{"今天天气很好", simplifiedchinese.GBK},
+		{"台北市政府", traditionalchinese.Big5},
+		{"歡迎光臨", traditionalchinese.Big5},
+		{koreanSample, korean.EUCKR},
+		{"안녕하세요, 반갑습니다", korean.EUCKR},
+		{"똠방각하는 뷁을 좋아한다", korean.EUCKR},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.enc.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		got, err := ioutil.ReadAll(NewReader(strings.NewReader(feed)))
+		if err != nil {
+			t.Errorf("error en ReadAll: %v", err)
+		}
+		if string(got) != tt.text {
+			t.Errorf("%d. %s -> got: %s - expected: %s", i, tt.enc, got, tt.text)
+		}
+	}
+}
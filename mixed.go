@@ -0,0 +1,52 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+// Stretch is a part of some content written in a single encoding, as ScanEncodings finds it
+type Stretch struct {
+	// Offset is the position in the content where the stretch starts
+	Offset int64
+	// Encoding is the name of the encoding of the stretch
+	Encoding string
+}
+
+// ScanEncodings reads r and returns the stretches of it written in different
+// encodings, so that files put together from several sources, such as concatenated logs, can be
+// split and repaired. The first stretch starts at 0 with the encoding detected from the preview,
+// as opts customize it; another starts at every line that isn't valid in the encoding of the
+// one before, or is valid UTF-8 when the encoding isn't UTF-8, with the encoding detected from
+// it. The encoding of the preview is checked even if told by a BOM or a Content-Type, but for
+// UTF-16, UTF-32 and UTF-7, whose content is a single stretch
+func ScanEncodings(r io.Reader, opts ...Option) ([]Stretch, error) {
+	o := newOptions(opts)
+	o.revalidation = RevalidateSwitch
+	o.strict = false
+	preview := make([]byte, o.preview())
+	n, err := io.ReadFull(r, preview)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	preview = preview[:n]
+
+	e, res, _ := newResult(preview, o)
+	stretches := []Stretch{{Offset: 0, Encoding: res.Encoding}}
+	res.Certain = false
+	if !revalidates(res, o) {
+		return stretches, nil
+	}
+	v := newRevalidator(e, res.Encoding, o)
+	v.redetected = func(offset int64, name string) {
+		if last := &stretches[len(stretches)-1]; last.Offset == offset {
+			last.Encoding = name
+		} else {
+			stretches = append(stretches, Stretch{Offset: offset, Encoding: name})
+		}
+	}
+	_, err = io.Copy(io.Discard, transform.NewReader(io.MultiReader(bytes.NewReader(preview), r), v))
+	return stretches, err
+}
@@ -0,0 +1,39 @@
+package txtopener
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanEncodings(t *testing.T) {
+	utf8Line := "a\xc3\xb1o nuevo en la monta\xc3\xb1a\n"
+	latinLine := "a\xf1o nuevo en la monta\xf1a\n"
+	koi8Line := "\xf0\xd2\xc9\xd7\xc5\xd4, \xcd\xc9\xd2! \xf0\xd2\xc9\xd7\xc5\xd4, \xcd\xc9\xd2!\n"
+
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected []Stretch
+	}{
+		{"", nil, []Stretch{{0, "ISO 8859-1"}}},
+		{utf8Line + utf8Line, nil, []Stretch{{0, "utf-8"}}},
+		{utf8Line + latinLine + utf8Line, nil, []Stretch{{0, "utf-8"}, {26, "ISO 8859-1"}, {50, "utf-8"}}},
+		{utf8Line + latinLine + latinLine, nil, []Stretch{{0, "utf-8"}, {26, "ISO 8859-1"}}},
+		{latinLine + utf8Line, []Option{WithPreviewSize(25)}, []Stretch{{0, "ISO 8859-1"}, {24, "utf-8"}}},
+		{string(utf8bom) + utf8Line + latinLine, nil, []Stretch{{0, "utf-8"}, {29, "ISO 8859-1"}}},
+		{string(utf16lebom) + "a\x00\xe9\x00", nil, []Stretch{{0, "utf-16le"}}},
+		{utf8Line + strings.Repeat(koi8Line, 4), nil, []Stretch{{0, "utf-8"}, {26, "koi8-r"}}},
+	}
+
+	for i, tt := range tests {
+		got, err := ScanEncodings(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en ScanEncodings: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. feeded: %q -> got: %v - expected: %v", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package txtopener
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/transform"
+)
+
+// RepairMojibake is a transformer of UTF-8 text that reverses double encoding: UTF-8 that was
+// decoded as ISO-8859-1 or windows-1252 and encoded again as UTF-8, so that "café" reads "cafÃ©".
+// Every run of characters whose bytes in those encodings make up a UTF-8 sequence beyond ASCII is
+// replaced with the character of the sequence. It can be chained to other transformers, and
+// WithMojibakeRepair applies it to the output of the readers
+var RepairMojibake transform.Transformer = mojibakeRepairer{}
+
+// WithMojibakeRepair makes the reader reverse the double encoding of UTF-8 in its output, as
+// RepairMojibake does, whatever the encoding of the input. It is meant for dumps of databases
+// that stored UTF-8 in Latin-1 columns
+func WithMojibakeRepair() Option {
+	return func(o *options) {
+		o.mojibake = true
+	}
+}
+
+// mojibakeTrail maps back to its byte every character ISO-8859-1 or windows-1252 decode a
+// continuation byte of UTF-8, 0x80 to 0xBF, as
+var mojibakeTrail = func() map[rune]byte {
+	m := make(map[rune]byte)
+	for b := 0x80; b < 0xc0; b++ {
+		m[rune(b)] = byte(b)
+		if r := decodeByte(charmap.Windows1252, byte(b)); r != utf8.RuneError {
+			m[r] = byte(b)
+		}
+	}
+	return m
+}()
+
+type mojibakeRepairer struct {
+	transform.NopResetter
+}
+
+func (mojibakeRepairer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if len(dst)-nDst < utf8.UTFMax {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size, short := doubleEncoded(src[nSrc:], atEOF)
+		switch {
+		case short:
+			return nDst, nSrc, transform.ErrShortSrc
+		case size > 0:
+			nDst += utf8.EncodeRune(dst[nDst:], r)
+			nSrc += size
+		default:
+			_, size = utf8.DecodeRune(src[nSrc:])
+			nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+			nSrc += size
+		}
+	}
+	return nDst, nSrc, nil
+}
+
+// doubleEncoded returns the character b starts with the double encoding of, and the size of
+// the encoding, 0 if b doesn't start with one. short tells that b ends before it can be told
+func doubleEncoded(b []byte, atEOF bool) (r rune, size int, short bool) {
+	lead, n := utf8.DecodeRune(b)
+	if lead == utf8.RuneError && n < 2 {
+		return 0, 0, !atEOF && !utf8.FullRune(b)
+	}
+	var length int
+	switch {
+	case lead >= 0xc2 && lead <= 0xdf:
+		length = 2
+	case lead >= 0xe0 && lead <= 0xef:
+		length = 3
+	case lead >= 0xf0 && lead <= 0xf4:
+		length = 4
+	default:
+		return 0, 0, false
+	}
+	seq := []byte{byte(lead)}
+	for size = n; len(seq) < length; size += n {
+		if size == len(b) || !utf8.FullRune(b[size:]) {
+			return 0, 0, !atEOF
+		}
+		var c rune
+		c, n = utf8.DecodeRune(b[size:])
+		trail, ok := mojibakeTrail[c]
+		if !ok {
+			return 0, 0, false
+		}
+		seq = append(seq, trail)
+	}
+	if r, n = utf8.DecodeRune(seq); r == utf8.RuneError || n != len(seq) {
+		return 0, 0, false
+	}
+	return r, size, false
+}
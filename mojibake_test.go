@@ -0,0 +1,63 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/transform"
+)
+
+func TestRepairMojibake(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{"café", "café"},
+		{"cafÃ©", "café"},
+		{"aÃ±o Ã±andÃº", "año ñandú"},
+		{"â€œquotedâ€\u009d", "“quoted”"},
+		{"â‚¬ 5", "€ 5"},
+		{"ðŸ˜€", "😀"},
+		{"Ã", "Ã"},
+		{"Ã and Â", "Ã and Â"},
+		{"MÃœNCHEN Ã¼ber", "MÜNCHEN über"},
+		{"\xff invalid", "\xff invalid"},
+		{"", ""},
+	}
+
+	for i, tt := range tests {
+		if got, _, err := transform.String(RepairMojibake, tt.feed); err != nil || got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q", i, tt.feed, got, err, tt.expected)
+		}
+		r := transform.NewReader(iotest.OneByteReader(strings.NewReader(tt.feed)), RepairMojibake)
+		if got, err := ioutil.ReadAll(r); err != nil || string(got) != tt.expected {
+			t.Errorf("%d. feeded byte by byte: %q -> got: %q, %v - expected: %q", i, tt.feed, got, err, tt.expected)
+		}
+	}
+}
+
+func TestWithMojibakeRepair(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+	}{
+		{"cafÃ©", []Option{WithMojibakeRepair()}, "café"},
+		{"cafÃ©", nil, "cafÃ©"},
+		{"caf\xc3\xa9", []Option{WithMojibakeRepair(), WithContentType("text/plain; charset=windows-1252")}, "café"},
+		{"cafÃ©", []Option{WithMojibakeRepair(), WithStrict()}, "café"},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
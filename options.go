@@ -0,0 +1,257 @@
+package txtopener
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Option customizes how the readers returned by NewReaderOpts detect and convert their input
+type Option func(*options)
+
+type options struct {
+	contentType string
+	fragment    bool
+	detectors   []Detector
+
+	visualToLogical bool
+
+	// fallback is used instead of ISO-8859-1 when nothing tells the encoding
+	fallback     encoding.Encoding
+	fallbackName string
+	// base64 looks for a Base64 layer around the text
+	base64 bool
+	// strict fails on input not valid for its encoding instead of replacing it with U+FFFD
+	strict bool
+	// stats collects the figures of the readers
+	stats *Stats
+	// seekable is the size up to which the output is buffered whole
+	seekable int
+	// allowed holds the names of the encodings allowed, all if nil
+	allowed map[string]bool
+	// deterministic checks that the detectors always give the same answer
+	deterministic bool
+	// previewSize is the number of bytes examined to detect the encoding, defaultPreviewSize if 0
+	previewSize int
+	// bomPolicy tells what to do with the BOM of the input
+	bomPolicy BOMPolicy
+	// rejectGuessed fails on input whose encoding is guessed
+	rejectGuessed bool
+	// rejectBinary fails on input that looks binary
+	rejectBinary bool
+	// revalidation tells what to do with the input that contradicts the encoding guessed
+	revalidation RevalidationPolicy
+	// samples is the number of windows DetectAt samples besides the head
+	samples int
+	// mojibake reverses the double encoding of UTF-8 in the output
+	mojibake bool
+	// innerBOMs drops the BOMs past the start of the input
+	innerBOMs bool
+	// scratch holds the buffers reused by a Reader, nil for the other readers
+	scratch *scratch
+	// filename is the name of the file read, whose extension hints the encoding
+	filename string
+	// language is the primary subtag of the language hint, lowercase, empty if none
+	language string
+	// noModels skips the language models of the detection
+	noModels bool
+	// replacement honors the labels of the WHATWG replacement encoding
+	replacement bool
+	// unencodable tells the writers what to do with the characters their encoding doesn't have
+	unencodable UnencodablePolicy
+}
+
+// defaultPreviewSize is the number of bytes examined to detect the encoding unless told otherwise
+const defaultPreviewSize = 10240
+
+// packagePreviewSize is the preview size of the readers not given WithPreviewSize, set with
+// SetDefaultPreviewSize, defaultPreviewSize if 0
+var packagePreviewSize atomic.Int64
+
+// preview returns the number of bytes examined to detect the encoding
+func (o *options) preview() int {
+	if o.previewSize > 0 {
+		return o.previewSize
+	}
+	return defaultPreview()
+}
+
+// defaultPreview returns the number of bytes examined by the readers not given WithPreviewSize
+func defaultPreview() int {
+	if n := packagePreviewSize.Load(); n > 0 {
+		return int(n)
+	}
+	return defaultPreviewSize
+}
+
+// newOptions returns the default options modified by opts
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// NewReaderOpts is like NewReader but its behavior is customized by opts and,
+// instead of panicking, it returns the errors found while reading the preview of r
+//
+// When r is an io.ReadSeeker, such as an *os.File, the reader returned by any of the constructors
+// is one too. Its offsets count decoded bytes, seeking backwards decodes r again from where it
+// was and seeking relative to io.SeekEnd isn't supported
+//
+// The readers make use of what files, *os.File and fs.File alike, tell about themselves: the
+// preview of a small file is only as big as the file, and the preview of a source that can seek
+// is read again from it instead of being kept in memory
+func NewReaderOpts(r io.Reader, opts ...Option) (io.Reader, error) {
+	return decodingReader(r, newOptions(opts))
+}
+
+// WithFragment treats the input as an HTML fragment (a snippet without <head>) instead of a full document:
+// a meta content="...; charset=..." is honored even without its http-equiv pragma and the
+// charset attribute of any element is taken as a hint
+func WithFragment() Option {
+	return func(o *options) {
+		o.fragment = true
+	}
+}
+
+// WithFallback makes the encoding labeled label the one taken when nothing tells the encoding of
+// the input, instead of ISO-8859-1. Unknown labels are ignored
+func WithFallback(label string) Option {
+	e, name := lookupLabel(label)
+	return func(o *options) {
+		if e != nil {
+			o.fallback, o.fallbackName = e, name
+		}
+	}
+}
+
+// fallback is an encoding taken when nothing tells the encoding of the input
+type fallback struct {
+	e    encoding.Encoding
+	name string
+}
+
+// defaultFallback is the fallback of the readers not given WithFallback
+var defaultFallback atomic.Pointer[fallback]
+
+func init() {
+	defaultFallback.Store(&fallback{charmap.ISO8859_1, "ISO 8859-1"})
+}
+
+// SetDefaultFallback makes the encoding labeled label the one taken by all the readers not given
+// WithFallback when nothing tells the encoding of the input, instead of ISO-8859-1. Most "latin1"
+// files in the wild are actually windows-1252, which gives 0x80-0x9F to punctuation such as “”
+func SetDefaultFallback(label string) error {
+	e, name, err := lookup(label)
+	if err != nil {
+		return err
+	}
+	defaultFallback.Store(&fallback{e, name})
+	return nil
+}
+
+// WithPreviewSize makes the reader examine the first n bytes of the input, instead of 10240 or
+// the size given to SetDefaultPreviewSize, to detect the encoding. A bigger preview helps with
+// text whose first non-ASCII bytes or meta element come late, a smaller one with streams that
+// have to start flowing soon
+func WithPreviewSize(n int) Option {
+	return func(o *options) {
+		o.previewSize = n
+	}
+}
+
+// SetDefaultPreviewSize makes all the readers not given WithPreviewSize, and the functions
+// detecting the encoding of files and streams, examine the first n bytes of their input instead
+// of 10240. A size of 0 or less restores 10240
+func SetDefaultPreviewSize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	packagePreviewSize.Store(int64(n))
+}
+
+// DefaultPreviewSize returns the number of bytes examined by the readers not given
+// WithPreviewSize, as SetDefaultPreviewSize left it
+func DefaultPreviewSize() int {
+	return defaultPreview()
+}
+
+// WithContentType makes the charset of contentType, such as the header of an HTTP response,
+// take part in the detection: only a BOM wins over it
+func WithContentType(contentType string) Option {
+	return func(o *options) {
+		o.contentType = contentType
+	}
+}
+
+// WithStrict makes the reader fail with ErrInvalidInput when the input isn't valid in its
+// encoding, instead of replacing it with U+FFFD
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// BOMPolicy tells what the readers do with the byte order mark of their input
+type BOMPolicy int
+
+const (
+	// BOMStrip drops the BOM, the default
+	BOMStrip BOMPolicy = iota
+	// BOMPreserve keeps the BOM, written as the UTF-8 BOM
+	BOMPreserve
+	// BOMAdd starts the output with the UTF-8 BOM whether the input had a BOM or not, as
+	// Excel and old Windows tools need to tell UTF-8 apart
+	BOMAdd
+)
+
+// utf8BOM is the UTF-8 encoding of U+FEFF
+const utf8BOM = "\ufeff"
+
+// WithBOMPolicy makes the reader handle the BOM of the input according to policy
+func WithBOMPolicy(policy BOMPolicy) Option {
+	return func(o *options) {
+		o.bomPolicy = policy
+	}
+}
+
+// WithLanguageHint tells the detection the language the input is most likely written in, as a
+// BCP 47 tag such as "ja", "ru" or "el-GR", so that the language models prefer the charsets
+// plausible for it: Shift_JIS and EUC-JP for Japanese, windows-1251 and KOI8-R for Russian.
+// The hint only helps the guesses; a BOM, a declaration or valid UTF-8 still win over it
+func WithLanguageHint(tag string) Option {
+	lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	return func(o *options) {
+		o.language = lang
+	}
+}
+
+// WithoutModels turns off the language models of the detection, so that the input not told by
+// a BOM, a declaration, valid UTF-8 or a detector is taken for the fallback encoding, as it was
+// before they were added, and the detection is cheaper
+func WithoutModels() Option {
+	return func(o *options) {
+		o.noModels = true
+	}
+}
+
+// WithReplacementEncoding makes the charsets the WHATWG maps to the replacement encoding, such
+// as ISO-2022-KR, HZ-GB-2312 and ISO-2022-CN, decode the whole input as a single U+FFFD, as
+// browsers do so that text in them can't smuggle markup past filters. Without it a Content-Type
+// or a declaration naming one of them is ignored, as one naming an unknown charset is
+func WithReplacementEncoding() Option {
+	return func(o *options) {
+		o.replacement = true
+	}
+}
+
+// honored tells whether the detection takes the encoding named name when declared
+func (o *options) honored(name string) bool {
+	return name != "replacement" || o.replacement
+}
@@ -0,0 +1,119 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestOptions(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+		err      error
+	}{
+		{"\x93caf\xe9\x94", []Option{WithFallback("windows-1252")}, "“café”", nil},
+		{"\x93caf\xe9\x94", []Option{WithFallback("no-such-encoding")}, "\u0093café\u0094", nil},
+		{"abcdefghijkl\xc3\xa9", nil, "abcdefghijklé", nil},
+		{"abcdefghijkl\xc3\xa9", []Option{WithPreviewSize(8)}, "abcdefghijklÃ©", nil},
+		{"\xcf\xf0\xe8", []Option{WithContentType("text/plain; charset=windows-1251")}, "При", nil},
+		{string(utf8bom) + "a", []Option{WithContentType("text/plain; charset=koi8-r")}, "a", nil},
+		{"ping\xc3\xbc\xc3", []Option{WithStrict()}, "", ErrInvalidInput},
+		{string(utf8bom) + "café", []Option{WithBOMPolicy(BOMStrip)}, "café", nil},
+		{string(utf8bom) + "café", []Option{WithBOMPolicy(BOMPreserve)}, string(utf8bom) + "café", nil},
+		{string(utf16lebom) + "\xe9\x00", []Option{WithBOMPolicy(BOMPreserve)}, string(utf8bom) + "é", nil},
+		{"caf\xe9", []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom) + "café", nil},
+		{string(utf8bom) + "café", []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom) + "café", nil},
+		{string(utf16bebom) + "\x00\xe9", []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom) + "é", nil},
+		{"", []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom), nil},
+		{"caf\xe9 <b>", []Option{WithContentType("text/html; charset=iso-2022-kr")}, "café <b>", nil},
+		{"caf\xe9 <b>", []Option{WithContentType("text/html; charset=iso-2022-kr"), WithReplacementEncoding()}, "\ufffd", nil},
+		{`<meta charset="hz-gb-2312">` + "caf\xe9", nil, `<meta charset="hz-gb-2312">café`, nil},
+		{`<meta charset="hz-gb-2312">` + "caf\xe9", []Option{WithReplacementEncoding()}, "\ufffd", nil},
+	}
+
+	for i, tt := range tests {
+		var got []byte
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err == nil {
+			got, err = ioutil.ReadAll(r)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. feeded: %q -> got error: %v - expected: %v", i, tt.feed, err, tt.err)
+			continue
+		}
+		if tt.err == nil && string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestSetDefaultFallback(t *testing.T) {
+	saved := defaultFallback.Load()
+	t.Cleanup(func() { defaultFallback.Store(saved) })
+
+	if err := SetDefaultFallback("no-such-encoding"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("got: %v - expected: %v", err, ErrUnknownEncoding)
+	}
+	if err := SetDefaultFallback("windows-1252"); err != nil {
+		t.Fatalf("error en SetDefaultFallback: %v", err)
+	}
+
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+	}{
+		{"\x93caf\xe9\x94", nil, "“café”"},
+		{"caf\xe9", []Option{WithFallback("koi8-r")}, "cafИ"},
+	}
+	for i, tt := range tests {
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+	if _, name, _ := DetectEncoding([]byte("\x93"), ""); name != "windows-1252" {
+		t.Errorf("got: %q - expected: %q", name, "windows-1252")
+	}
+}
+
+func TestSetDefaultPreviewSize(t *testing.T) {
+	defer SetDefaultPreviewSize(0)
+	late := strings.Repeat(" ", 12000) + `<meta charset="koi8-r">` + "\xf0\xd2\xc9\xd7\xc5\xd4"
+
+	var tests = []struct {
+		size     int
+		feed     string
+		opts     []Option
+		expected string
+	}{
+		{8, "abcdefghijkl\xc3\xa9", nil, "abcdefghijklÃ©"},
+		{8, "abcdefghijkl\xc3\xa9", []Option{WithPreviewSize(100)}, "abcdefghijklé"},
+		{0, "abcdefghijkl\xc3\xa9", nil, "abcdefghijklé"},
+		{0, late, nil, strings.Repeat(" ", 12000) + `<meta charset="koi8-r">` + "ðÒÉ×ÅÔ"},
+		{20000, late, nil, strings.Repeat(" ", 12000) + `<meta charset="koi8-r">Привет`},
+		{-1, late, nil, strings.Repeat(" ", 12000) + `<meta charset="koi8-r">` + "ðÒÉ×ÅÔ"},
+	}
+
+	for i, tt := range tests {
+		SetDefaultPreviewSize(tt.size)
+		if n := DefaultPreviewSize(); tt.size > 0 && n != tt.size || tt.size <= 0 && n != 10240 {
+			t.Errorf("%d. DefaultPreviewSize -> got: %d - expected: %d", i, n, tt.size)
+		}
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package txtopener
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// Profile is a set of options suited to a kind of input. It can be passed to NewReaderOpts
+// as is, with WebScrape..., or together with other options through WithProfile
+type Profile []Option
+
+var (
+	// WebScrape is meant for pages and snippets fetched from the web: the input is treated as
+	// an HTML fragment and, like browsers do, undeclared legacy text is taken for windows-1252
+	WebScrape = Profile{WithFragment(), WithFallback("windows-1252")}
+
+	// WindowsLegacy is meant for text files written by Windows programs with the ANSI code page:
+	// undeclared legacy text is taken for windows-1252 instead of ISO-8859-1
+	WindowsLegacy = Profile{WithFallback("windows-1252")}
+
+	// StrictArchival is meant for archival pipelines which must not alter the text silently:
+	// the reader fails with ErrInvalidInput instead of replacing invalid input with U+FFFD
+	StrictArchival = Profile{WithStrict()}
+)
+
+// WithProfile applies the options of p. Options given after it override them
+func WithProfile(p Profile) Option {
+	return func(o *options) {
+		for _, opt := range p {
+			opt(o)
+		}
+	}
+}
+
+// ErrInvalidInput is returned by the strict readers when the input isn't valid in its encoding
+var ErrInvalidInput = errors.New("txtopener: input not valid in its encoding")
+
+// strictUTF8 is a transformer that passes valid UTF-8 through and fails on anything else,
+// including the U+FFFD the decoders put in place of invalid input
+type strictUTF8 struct {
+	transform.NopResetter
+}
+
+func (strictUTF8) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError {
+			if size == 1 && !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			return nDst, nSrc, ErrInvalidInput
+		}
+		if nDst+size > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], src[nSrc:nSrc+size])
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,40 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+		err      error
+	}{
+		{"\x93caf\xe9\x94", nil, "\u0093café\u0094", nil},
+		{"\x93caf\xe9\x94", WindowsLegacy, "“café”", nil},
+		{"<p>ni\xf1o</p>", WebScrape, "<p>niño</p>", nil},
+		{"<p>\x93ni\xf1o\x94</p>", []Option{WithProfile(WebScrape), WithVisualToLogical()}, "<p>“niño”</p>", nil},
+		{"pingüino", StrictArchival, "pingüino", nil},
+		{"ping\xc3\xbc\xc3", StrictArchival, "", ErrInvalidInput},
+		{"\xff\xfe\x00\xd8a\x00", StrictArchival, "", ErrInvalidInput},
+	}
+
+	for i, tt := range tests {
+		var got []byte
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err == nil {
+			got, err = ioutil.ReadAll(r)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. feeded: %q -> got error: %v - expected: %v", i, tt.feed, err, tt.err)
+			continue
+		}
+		if tt.err == nil && string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package txtopener
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Reader converts the content of its source to UTF-8 as the readers returned by NewReaderOpts do,
+// but it can be Reset to read another source reusing its buffers and decoders, so that services
+// reading many small inputs can keep Readers in a sync.Pool. The detection runs on the first Read
+// after NewResettableReader or Reset, and the errors reading the preview are returned by Read
+//
+// A Reader is an io.RuneScanner as well, so it can be given directly to text/scanner,
+// regexp.MatchReader and the like
+type Reader struct {
+	lazy lazyReader
+	s    scratch
+	br   *bufio.Reader
+}
+
+// NewResettableReader returns a Reader converting the content of r to UTF-8, customized by opts
+func NewResettableReader(r io.Reader, opts ...Option) *Reader {
+	rd := &Reader{}
+	rd.lazy.o = newOptions(opts)
+	rd.lazy.o.scratch = &rd.s
+	rd.br = bufio.NewReader(&rd.lazy)
+	rd.Reset(r)
+	return rd
+}
+
+func (rd *Reader) Read(p []byte) (int, error) {
+	return rd.br.Read(p)
+}
+
+// ReadRune reads a single UTF-8 encoded character from the decoded output
+func (rd *Reader) ReadRune() (r rune, size int, err error) {
+	return rd.br.ReadRune()
+}
+
+// UnreadRune unreads the last rune read by ReadRune
+func (rd *Reader) UnreadRune() error {
+	return rd.br.UnreadRune()
+}
+
+// Peek returns the next n bytes of the decoded output without consuming them. The detection runs
+// if it hasn't already. Like bufio.Reader.Peek, it returns an error when fewer than n bytes are
+// available, and the bytes aren't valid after the next read
+func (rd *Reader) Peek(n int) ([]byte, error) {
+	return rd.br.Peek(n)
+}
+
+// Preview returns up to the first n bytes of the raw input examined by the detection, as they
+// were before decoding, so that file signatures can be sniffed. The detection runs if it hasn't
+// already. The bytes aren't valid after Reset
+func (rd *Reader) Preview(n int) ([]byte, error) {
+	if _, err := rd.br.Peek(1); err != nil && err != io.EOF {
+		return nil, err
+	}
+	p := rd.s.preview
+	if n < len(p) {
+		p = p[:n]
+	}
+	return p, nil
+}
+
+// Reset discards the state of rd and makes it read from r, detecting its encoding again
+// with the same options
+func (rd *Reader) Reset(r io.Reader) {
+	rd.lazy.src, rd.lazy.r, rd.lazy.err = r, nil, nil
+	rd.br.Reset(&rd.lazy)
+}
+
+// scratch holds what a Reader reuses across Resets
+type scratch struct {
+	preview []byte
+	name    string
+	t       transform.Transformer
+	tr      transformReader
+}
+
+// previewBuffer returns a buffer of n bytes for the preview, allocated if s is nil
+func (s *scratch) previewBuffer(n int) []byte {
+	if s == nil {
+		return make([]byte, n)
+	}
+	if cap(s.preview) < n {
+		s.preview = make([]byte, n)
+	}
+	return s.preview[:n]
+}
+
+// keepPreview records preview, the raw input read to detect the encoding, if s isn't nil
+func (s *scratch) keepPreview(preview []byte) {
+	if s != nil {
+		s.preview = preview
+	}
+}
+
+// decoder is like the function decoder but the transformer is kept for the next input as long
+// as its encoding is the same, and so are the buffers of the reader
+func (s *scratch) decoder(r io.Reader, e encoding.Encoding, name string, o *options) io.Reader {
+	if s.t == nil || name != s.name {
+		s.t, s.name = transformer(e, name, o), name
+	} else {
+		s.t.Reset()
+	}
+	if s.t == nil {
+		return r
+	}
+	s.tr.reset(r, s.t)
+	return &s.tr
+}
+
+var errInconsistentByteCount = errors.New("txtopener: inconsistent byte count returned")
+
+// transformReader is like transform.Reader but it can be reset to reuse its buffers
+type transformReader struct {
+	r   io.Reader
+	t   transform.Transformer
+	err error
+	// complete tells that t has been flushed, after reading or failing
+	complete bool
+
+	dst        []byte
+	dst0, dst1 int
+	src        []byte
+	src0, src1 int
+}
+
+// transformBufSize is the size of the buffers of a transformReader, that of transform.Reader
+const transformBufSize = 4096
+
+func (tr *transformReader) reset(r io.Reader, t transform.Transformer) {
+	if tr.dst == nil {
+		tr.dst, tr.src = make([]byte, transformBufSize), make([]byte, transformBufSize)
+	}
+	tr.r, tr.t, tr.err, tr.complete = r, t, nil, false
+	tr.dst0, tr.dst1, tr.src0, tr.src1 = 0, 0, 0, 0
+}
+
+func (tr *transformReader) Read(p []byte) (int, error) {
+	for {
+		// hand out what is already transformed, and the final error once done
+		if tr.dst0 != tr.dst1 {
+			n := copy(p, tr.dst[tr.dst0:tr.dst1])
+			tr.dst0 += n
+			if tr.dst0 == tr.dst1 && tr.complete {
+				return n, tr.err
+			}
+			return n, nil
+		} else if tr.complete {
+			return 0, tr.err
+		}
+
+		// transform what is read, or flush the transformer at the end of the input
+		if tr.src0 != tr.src1 || tr.err != nil {
+			var n int
+			var err error
+			tr.dst0 = 0
+			tr.dst1, n, err = tr.t.Transform(tr.dst, tr.src[tr.src0:tr.src1], tr.err == io.EOF)
+			tr.src0 += n
+
+			switch {
+			case err == nil:
+				if tr.src0 != tr.src1 {
+					tr.err = errInconsistentByteCount
+				}
+				tr.complete = tr.err != nil
+				continue
+			case err == transform.ErrShortDst && (tr.dst1 != 0 || n != 0):
+				continue
+			case err == transform.ErrShortSrc && tr.src1-tr.src0 != len(tr.src) && tr.err == nil:
+				// more input is needed
+			default:
+				tr.complete = true
+				if tr.err == nil || tr.err == io.EOF {
+					tr.err = err
+				}
+				continue
+			}
+		}
+
+		if tr.src0 != 0 {
+			tr.src0, tr.src1 = 0, copy(tr.src, tr.src[tr.src0:tr.src1])
+		}
+		var n int
+		n, tr.err = tr.r.Read(tr.src[tr.src1:])
+		tr.src1 += n
+	}
+}
@@ -0,0 +1,131 @@
+package txtopener
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestReaderReset(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+		err      error
+	}{
+		{string(utf8bom) + "café", "café", nil},
+		{"caf\xe9", "café", nil},
+		{"na\xefve", "naïve", nil},
+		{string(utf16lebom) + "\xe9\x00", "é", nil},
+		{"", "", nil},
+		{strings.Repeat("caf\xe9 ", 5000), strings.Repeat("café ", 5000), nil},
+		{"ping\xc3\xbc\xc3", "", ErrInvalidInput},
+		{"pingüino", "pingüino", nil},
+	}
+
+	r := NewResettableReader(nil, WithStrict())
+	for i, tt := range tests {
+		r.Reset(strings.NewReader(tt.feed))
+		got, err := ioutil.ReadAll(r)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d. feeded: %q -> got error: %v - expected: %v", i, tt.feed, err, tt.err)
+			continue
+		}
+		if tt.err == nil && string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestReaderAllocs(t *testing.T) {
+	feed := strings.Repeat("caf\xe9 ", 1000)
+	r := NewResettableReader(strings.NewReader(feed))
+	buf := make([]byte, 1024)
+	read := func(r io.Reader) {
+		for {
+			if _, err := r.Read(buf); err != nil {
+				break
+			}
+		}
+	}
+	read(r)
+
+	reused := testing.AllocsPerRun(10, func() {
+		r.Reset(strings.NewReader(feed))
+		read(r)
+	})
+	fresh := testing.AllocsPerRun(10, func() {
+		nr, _ := NewReaderOpts(strings.NewReader(feed))
+		read(nr)
+	})
+	if reused >= fresh {
+		t.Errorf("got %g allocations reusing the reader - expected fewer than %g", reused, fresh)
+	}
+}
+
+func TestReaderRunes(t *testing.T) {
+	r := NewResettableReader(strings.NewReader("ni\xf1o \x93x\x94"), WithFallback("windows-1252"))
+	var got []rune
+	for {
+		c, size, err := r.ReadRune()
+		if err != nil {
+			break
+		}
+		if c == 'ñ' {
+			if size != 2 {
+				t.Errorf("size of %q -> got: %d - expected: 2", c, size)
+			}
+			if err := r.UnreadRune(); err != nil {
+				t.Fatalf("error en UnreadRune: %v", err)
+			}
+			if again, _, _ := r.ReadRune(); again != c {
+				t.Errorf("after UnreadRune -> got: %q - expected: %q", again, c)
+			}
+		}
+		got = append(got, c)
+	}
+	if string(got) != "niño “x”" {
+		t.Errorf("got: %q - expected: %q", string(got), "niño “x”")
+	}
+
+	r.Reset(strings.NewReader("caf\xe9 = 1"))
+	if !regexp.MustCompile(`é\s*=`).MatchReader(r) {
+		t.Errorf("regexp.MatchReader didn't match the decoded output")
+	}
+}
+
+func TestReaderPeek(t *testing.T) {
+	r := NewResettableReader(strings.NewReader("#!/bin/sh\necho caf\xe9\n"))
+	raw, err := r.Preview(2)
+	if err != nil || string(raw) != "#!" {
+		t.Errorf("Preview -> got: %q, %v - expected: %q", raw, err, "#!")
+	}
+	if raw, _ = r.Preview(100); string(raw) != "#!/bin/sh\necho caf\xe9\n" {
+		t.Errorf("Preview -> got: %q - expected the whole input", raw)
+	}
+	peeked, err := r.Peek(15)
+	if err != nil || string(peeked) != "#!/bin/sh\necho " {
+		t.Errorf("Peek -> got: %q, %v - expected: %q", peeked, err, "#!/bin/sh\necho ")
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "#!/bin/sh\necho café\n" {
+		t.Errorf("after Peek -> got: %q - expected: %q", got, "#!/bin/sh\necho café\n")
+	}
+
+	r.Reset(strings.NewReader(string(utf16lebom) + "a\x00,\x00b\x00"))
+	if raw, _ = r.Preview(4); string(raw) != string(utf16lebom)+"a\x00" {
+		t.Errorf("Preview -> got: %q - expected: %q", raw, string(utf16lebom)+"a\x00")
+	}
+	if peeked, _ = r.Peek(3); string(peeked) != "a,b" {
+		t.Errorf("Peek -> got: %q - expected: %q", peeked, "a,b")
+	}
+	if _, err := r.Peek(4); err != io.EOF {
+		t.Errorf("Peek past the end -> got: %v - expected: %v", err, io.EOF)
+	}
+
+	r.Reset(strings.NewReader(""))
+	if raw, err = r.Preview(4); err != nil || len(raw) != 0 {
+		t.Errorf("Preview of nothing -> got: %q, %v", raw, err)
+	}
+}
@@ -0,0 +1,96 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"golang.org/x/text/encoding"
+)
+
+// Report tells what was found out about the encoding of some content
+type Report struct {
+	// Encoding is the name of the encoding of the content
+	Encoding string
+	// BOM tells whether the content starts with a byte order mark
+	BOM bool
+	// Certain tells whether the encoding was told by a BOM or a Content-Type, which the content
+	// can't contradict. A declaration in the content or valid UTF-8 isn't Certain, nor Guessed
+	Certain bool
+	// Confidence, from 0 to 1, is how likely the encoding is right: 1 when it was told, less the
+	// weaker the evidence. Plain ASCII taken as the fallback is 1 as well, it reads the same in any
+	// of them
+	Confidence float64
+	// Evidence tells which step of the detection decided the encoding
+	Evidence Evidence
+}
+
+// Result tells how a reader decodes its input
+type Result struct {
+	// Encoding is the name of the encoding of the input
+	Encoding string
+	// BOM is the name of the encoding of the byte order mark the input starts with, if any:
+	// utf-8, utf-16le, utf-16be, utf-32le, utf-32be or utf-7
+	BOM string
+	// Certain tells whether the encoding was told by a BOM or a Content-Type, which the content
+	// can't contradict. A declaration in the content or valid UTF-8 isn't Certain, nor Guessed
+	Certain bool
+	// Confidence, from 0 to 1, is how likely the encoding is right: 1 when it was told, less the
+	// weaker the evidence. Plain ASCII taken as the fallback is 1 as well, it reads the same in any
+	// of them
+	Confidence float64
+	// Evidence tells which step of the detection decided the encoding
+	Evidence Evidence
+}
+
+// newResult determines the encoding of preview, the start of some content
+func newResult(preview []byte, o *options) (encoding.Encoding, Result, Evidence) {
+	e, name, src, confidence := detectEncoding(preview, o)
+	res := Result{Encoding: name, Certain: src <= EvidenceContentType, Confidence: confidence, Evidence: src}
+	for _, b := range boms {
+		if bytes.HasPrefix(preview, b.bom) {
+			res.BOM = b.enc
+			break
+		}
+	}
+	if hasUTF7Signature(preview) {
+		res.BOM = "utf-7"
+	}
+	return e, res, src
+}
+
+// report returns the Report equivalent to res
+func (res Result) report() Report {
+	return Report{Encoding: res.Encoding, BOM: res.BOM != "", Certain: res.Certain, Confidence: res.Confidence,
+		Evidence: res.Evidence}
+}
+
+// hasBOM tells whether content starts with one of the known byte order marks
+func hasBOM(content []byte) bool {
+	for _, b := range boms {
+		if bytes.HasPrefix(content, b.bom) {
+			return true
+		}
+	}
+	return hasUTF7Signature(content)
+}
+
+// DetectFile reports the encoding of the named file reading only its first 10240 bytes, or as
+// many as SetDefaultPreviewSize tells, the preview NewReader determines it from
+func DetectFile(name string) (Report, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+
+	o := newOptions([]Option{WithFilename(name)})
+	preview := make([]byte, o.preview())
+	n, err := io.ReadFull(f, preview)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return Report{}, err
+	}
+	preview = preview[:n]
+	_, res, _ := newResult(preview, o)
+	return res.report(), nil
+}
@@ -0,0 +1,97 @@
+package txtopener
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestDetectFile(t *testing.T) {
+	var tests = []struct {
+		content  string
+		expected Report
+	}{
+		{"caf\xe9", Report{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+		{string(utf8bom) + "año", Report{Encoding: "utf-8", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{string(utf16lebom) + "a\x00", Report{Encoding: "utf-16le", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{"añejo" + string(bytes.Repeat([]byte("a"), 11000)) + "\xff", Report{Encoding: "utf-8", Confidence: 0.9, Evidence: EvidenceUTF8}},
+		{"", Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		name := filepath.Join(dir, "detect.txt")
+		if err := os.WriteFile(name, []byte(tt.content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		got, err := DetectFile(name)
+		if err != nil {
+			t.Errorf("%d. error en DetectFile: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. got: %+v - expected: %+v", i, got, tt.expected)
+		}
+	}
+}
+
+func TestResultConfidence(t *testing.T) {
+	koi8, _ := charmap.KOI8R.NewEncoder().String(russianSample)
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		evidence Evidence
+		min, max float64
+	}{
+		{string(utf8bom) + "año", nil, EvidenceBOM, 1, 1},
+		{"caf\xe9", []Option{WithContentType("text/plain; charset=windows-1252")}, EvidenceContentType, 1, 1},
+		{`<meta charset="koi8-r">` + koi8, nil, EvidenceMeta, 0.9, 0.9},
+		{`<?xml version="1.0" encoding="koi8-r"?>` + koi8, nil, EvidenceXMLDeclaration, 0.9, 0.9},
+		{`@charset "koi8-r";` + koi8, nil, EvidenceCSSCharset, 0.9, 0.9},
+		{`<p charset="koi8-r">` + koi8, []Option{WithFragment()}, EvidenceMeta, 0.9, 0.9},
+		{"caf\xc3\xa9", nil, EvidenceUTF8, 0.9, 0.9},
+		{"a\xc3\xb1o a\xc3\xb1ejo", nil, EvidenceUTF8, 0.99, 0.99},
+		{"caf\xe9", []Option{WithDetector(claim(charmap.Windows1250, "windows-1250", 0.7))}, EvidenceDetector, 0.7, 0.7},
+		{"caf\xe9", []Option{WithDetector(claim(charmap.Windows1250, "windows-1250", 3))}, EvidenceDetector, 1, 1},
+		{koi8, nil, EvidenceModel, 0.5, 1},
+		{"caf\xe9", nil, EvidenceFallback, 0, 0},
+		{"cafe", nil, EvidenceFallback, 1, 1},
+	}
+
+	for i, tt := range tests {
+		_, res, _ := newResult([]byte(tt.feed), newOptions(tt.opts))
+		if res.Evidence != tt.evidence || res.Confidence < tt.min || res.Confidence > tt.max {
+			t.Errorf("%d. feeded: %q -> got: %v %v - expected: %v in [%v, %v]", i, tt.feed, res.Evidence,
+				res.Confidence, tt.evidence, tt.min, tt.max)
+		}
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	var tests = []struct {
+		evidence Evidence
+		name     string
+		guessed  bool
+	}{
+		{EvidenceBOM, "bom", false},
+		{EvidenceContentType, "content-type", false},
+		{EvidenceXMLDeclaration, "xml-declaration", false},
+		{EvidenceCSSCharset, "css-charset", false},
+		{EvidenceMeta, "meta", false},
+		{EvidenceUTF8, "utf-8", false},
+		{EvidenceDetector, "detector", true},
+		{EvidenceModel, "model", true},
+		{EvidenceExtension, "extension", true},
+		{EvidenceFallback, "fallback", true},
+		{0, "Evidence(0)", false},
+	}
+
+	for i, tt := range tests {
+		if tt.evidence.String() != tt.name || tt.evidence.Guessed() != tt.guessed {
+			t.Errorf("%d. got: %s %v - expected: %s %v", i, tt.evidence, tt.evidence.Guessed(), tt.name, tt.guessed)
+		}
+	}
+}
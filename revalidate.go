@@ -0,0 +1,175 @@
+package txtopener
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// RevalidationPolicy tells what the readers do when the input past the preview contradicts the
+// encoding guessed from it
+type RevalidationPolicy int
+
+const (
+	// RevalidateNone trusts the guess to the end of the input, the default
+	RevalidateNone RevalidationPolicy = iota
+	// RevalidateFail fails with a *GuessContradictedError at the line that contradicts the guess,
+	// once the lines before it have been read
+	RevalidateFail
+	// RevalidateSwitch detects the encoding again from the line that contradicts the guess and
+	// decodes the rest of the input with the one detected, so that files put together from
+	// several sources read right line by line
+	RevalidateSwitch
+)
+
+// GuessContradictedError is returned by the readers created WithRevalidation(RevalidateFail)
+// when the input contradicts the encoding guessed for it
+type GuessContradictedError struct {
+	// Encoding is the name of the encoding guessed
+	Encoding string
+	// Offset is the position in the input of the line that contradicts it, everything before
+	// was decoded and read
+	Offset int64
+}
+
+func (e *GuessContradictedError) Error() string {
+	return fmt.Sprintf("txtopener: input at byte %d contradicts the guessed encoding %s", e.Offset, e.Encoding)
+}
+
+// WithRevalidation makes the reader keep checking, line by line, the input whose encoding wasn't
+// told by a BOM or a Content-Type and handle by policy the lines that contradict it: those not
+// valid in the encoding and, unless it is UTF-8, those valid as UTF-8 beyond ASCII. The guesses
+// of UTF-16, UTF-32 and UTF-7, which have no lines to check, are trusted
+func WithRevalidation(policy RevalidationPolicy) Option {
+	return func(o *options) {
+		o.revalidation = policy
+	}
+}
+
+// revalidationWindow is how long a line gets before it is checked in pieces
+const revalidationWindow = 1024
+
+// revalidates tells whether the input detected as res tells is to be revalidated
+func revalidates(res Result, o *options) bool {
+	if o.revalidation == RevalidateNone || res.Certain {
+		return false
+	}
+	for _, prefix := range []string{"utf-16", "utf-32", "utf-7"} {
+		if strings.HasPrefix(res.Encoding, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// revalidator is a transformer that decodes its input line by line checking every line against
+// the encoding guessed
+type revalidator struct {
+	o *options
+	// e and name are the encoding of the input, guessed or detected again, and t decodes it
+	e    encoding.Encoding
+	name string
+	t    transform.Transformer
+	// guess and guessName are the encoding guessed from the preview
+	guess     encoding.Encoding
+	guessName string
+	// offset is the position in the input of src
+	offset int64
+	// checked is how much of the start of src was checked already, but not decoded yet
+	checked int
+	// redetected, if not nil, is called with the offset of every line that contradicts the
+	// encoding and the name of the one detected again from it
+	redetected func(offset int64, name string)
+}
+
+// newRevalidator returns the revalidator of the input detected as e, named name
+func newRevalidator(e encoding.Encoding, name string, o *options) *revalidator {
+	v := &revalidator{o: o, guess: e, guessName: name}
+	v.use(e, name)
+	return v
+}
+
+// use makes the revalidator decode from e, named name, from now on
+func (v *revalidator) use(e encoding.Encoding, name string) {
+	v.e, v.name, v.t = e, name, transformer(e, name, v.o)
+	if v.t == nil {
+		v.t = transform.Nop
+	}
+}
+
+func (v *revalidator) Reset() {
+	v.use(v.guess, v.guessName)
+	v.offset, v.checked = 0, 0
+}
+
+func (v *revalidator) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	defer func() { v.offset += int64(nSrc) }()
+	for nSrc < len(src) {
+		rest := src[nSrc:]
+		if v.checked > 0 {
+			checked := rest
+			if v.checked < len(rest) {
+				checked = rest[:v.checked]
+			}
+			n, m, err := v.t.Transform(dst[nDst:], checked, atEOF && len(checked) == len(rest))
+			nDst, nSrc, v.checked = nDst+n, nSrc+m, v.checked-m
+			if err == transform.ErrShortSrc && len(checked) < len(rest) {
+				// the piece of a long line ends with a character cut, it goes with the next piece
+				v.checked = 0
+			} else if err != nil {
+				return nDst, nSrc, err
+			}
+			continue
+		}
+
+		line, whole := rest, true
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line = rest[:i+1]
+		} else if !atEOF {
+			if len(rest) < revalidationWindow {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			line, whole = trimPartialRune(rest), false
+		}
+		if v.contradicts(line, whole) {
+			if v.o.revalidation == RevalidateFail {
+				return nDst, nSrc, &GuessContradictedError{Encoding: v.name, Offset: v.offset + int64(nSrc)}
+			}
+			detect := *v.o
+			detect.contentType = ""
+			e, name, _, _ := detectEncoding(line, &detect)
+			if name != v.name {
+				v.use(e, name)
+			}
+			if v.redetected != nil {
+				v.redetected(v.offset+int64(nSrc), name)
+			}
+		}
+		v.checked = len(line)
+	}
+	return nDst, nSrc, nil
+}
+
+// contradicts tells whether line, beyond ASCII, isn't valid in the encoding of the input, or is
+// valid UTF-8 when the encoding isn't UTF-8. A line that isn't whole may end with a character cut
+func (v *revalidator) contradicts(line []byte, whole bool) bool {
+	if isASCII(line) {
+		return false
+	}
+	valid := utf8.Valid(line)
+	if v.name == "utf-8" {
+		return !valid
+	}
+	if valid {
+		return true
+	}
+	decoded, err := v.e.NewDecoder().Bytes(line)
+	if !whole {
+		decoded = bytes.TrimSuffix(decoded, replacementChar)
+	}
+	return err != nil || bytes.Contains(decoded, replacementChar)
+}
@@ -0,0 +1,56 @@
+package txtopener
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestRevalidation(t *testing.T) {
+	ascii := strings.Repeat("plain ascii\n", 4)
+	long := strings.Repeat("añejo ", 400)
+
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+		offset   int64
+	}{
+		{ascii + "caf\xc3\xa9\n", []Option{WithRevalidation(RevalidateFail)}, ascii, 48},
+		{ascii + "caf\xc3\xa9\n", []Option{WithRevalidation(RevalidateSwitch)}, ascii + "café\n", -1},
+		{ascii + "caf\xc3\xa9\n", []Option{WithRevalidation(RevalidateNone)}, ascii + "cafÃ©\n", -1},
+		{"a\xc3\xb1o\n" + ascii + "caf\xe9\n", []Option{WithRevalidation(RevalidateFail)}, "año\n" + ascii, 53},
+		{"a\xc3\xb1o\n" + ascii + "caf\xe9\nfin", []Option{WithRevalidation(RevalidateSwitch)}, "año\n" + ascii + "café\nfin", -1},
+		{"caf\xe9\n" + ascii + "caf\xe9\n", []Option{WithRevalidation(RevalidateFail)}, "café\n" + ascii + "café\n", -1},
+		{ascii + "caf\xc3\xa9\n", []Option{WithRevalidation(RevalidateFail), WithContentType("text/plain; charset=iso-8859-1")},
+			ascii + "cafÃ©\n", -1},
+		{"a\xc3\xb1o " + long + "\n" + long, []Option{WithRevalidation(RevalidateFail)}, "año " + long + "\n" + long, -1},
+	}
+
+	for i, tt := range tests {
+		for _, slow := range []bool{false, true} {
+			var src = iotest.HalfReader(strings.NewReader(tt.feed))
+			if slow {
+				src = iotest.OneByteReader(strings.NewReader(tt.feed))
+			}
+			r, err := NewReaderOpts(src, append(tt.opts, WithPreviewSize(16))...)
+			if err != nil {
+				t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+				continue
+			}
+			got, err := ioutil.ReadAll(r)
+			var contradicted *GuessContradictedError
+			switch {
+			case tt.offset < 0 && err != nil:
+				t.Errorf("%d. feeded: %q -> got error: %v", i, tt.feed, err)
+			case tt.offset >= 0 && (!errors.As(err, &contradicted) || contradicted.Offset != tt.offset):
+				t.Errorf("%d. feeded: %q -> got error: %v - expected offset %d", i, tt.feed, err, tt.offset)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+)
+
+// sampleWindow is the size of the windows DetectAt samples besides the head
+const sampleWindow = 4096
+
+// WithSamples makes DetectAt sample n windows of the source besides its head, so that files
+// whose head is plain ASCII are detected from what comes later. The windows are picked at random,
+// but always the same for sources of the same size
+func WithSamples(n int) Option {
+	return func(o *options) {
+		o.samples = n
+	}
+}
+
+// DetectAt reports the encoding of r, a random-access source of size bytes such as an *os.File
+// or a memory-mapped file, as NewReader would determine it given the Content-Type contentType,
+// which may be empty. It reads the preview from the start of r, and the windows WithSamples asks
+// for, without wrapping or consuming it, so that r can be handed as is to another parser.
+// The parts of r that can't be read are left out of the sample
+func DetectAt(r io.ReaderAt, size int64, contentType string, opts ...Option) Report {
+	o := newOptions(opts)
+	if contentType != "" {
+		o.contentType = contentType
+	}
+	head := readAt(r, 0, int64(o.preview()), size)
+	sample := head
+	if o.samples > 0 && size > int64(len(head)) {
+		sample = append([]byte(nil), trimPartialRune(head)...)
+		rnd := rand.New(rand.NewSource(size))
+		for i := 0; i < o.samples; i++ {
+			off := int64(len(head))
+			span := size - off - sampleWindow
+			if span > 0 {
+				off += rnd.Int63n(span)
+			} else if i > 0 {
+				// the rest of r fits in a single window
+				break
+			}
+			window := readAt(r, off, sampleWindow, size)
+			// the window starts with the line it cuts, if it can, and ends before the character it cuts
+			if i := bytes.IndexByte(window, '\n'); i >= 0 {
+				window = window[i+1:]
+			}
+			sample = append(append(sample, '\n'), trimPartialRune(window)...)
+		}
+		o.previewSize = len(sample)
+	}
+	_, res, _ := newResult(sample, o)
+	return res.report()
+}
+
+// readAt returns the n bytes of r at off, fewer at the end of r, of size bytes, or on an error
+func readAt(r io.ReaderAt, off, n, size int64) []byte {
+	if off+n > size {
+		n = size - off
+	}
+	if n <= 0 {
+		return nil
+	}
+	b := make([]byte, n)
+	read, _ := r.ReadAt(b, off)
+	return b[:read]
+}
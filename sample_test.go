@@ -0,0 +1,41 @@
+package txtopener
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDetectAt(t *testing.T) {
+	ascii := strings.Repeat("plain ascii\n", 2000)
+	// the rest after the head fits in a single window
+	short := strings.Repeat("plain ascii\n", 900)
+	var tests = []struct {
+		content     string
+		contentType string
+		opts        []Option
+		expected    Report
+	}{
+		{"", "", nil, Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+		{string(utf8bom) + "año", "", nil, Report{Encoding: "utf-8", BOM: true, Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{"caf\xe9", "text/plain; charset=windows-1252", nil,
+			Report{Encoding: "windows-1252", Certain: true, Confidence: 1, Evidence: EvidenceContentType}},
+		{ascii + "a\xc3\xb1o\n" + ascii, "", nil, Report{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+		{ascii + strings.Repeat("a\xc3\xb1o\n", 2000), "", []Option{WithSamples(3)},
+			Report{Encoding: "utf-8", Confidence: 1, Evidence: EvidenceUTF8}},
+		{short + strings.Repeat("a\xc3\xb1o\n", 4), "", []Option{WithSamples(3)},
+			Report{Encoding: "utf-8", Confidence: 1 - 0.1*0.1*0.1*0.1, Evidence: EvidenceUTF8}},
+		{"caf\xe9", "", []Option{WithSamples(3)}, Report{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+	}
+
+	for i, tt := range tests {
+		r := bytes.NewReader([]byte(tt.content))
+		got := DetectAt(r, int64(len(tt.content)), tt.contentType, tt.opts...)
+		if got != tt.expected {
+			t.Errorf("%d. got: %+v - expected: %+v", i, got, tt.expected)
+		}
+		if r.Len() != len(tt.content) {
+			t.Errorf("%d. the source was consumed", i)
+		}
+	}
+}
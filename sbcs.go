@@ -0,0 +1,321 @@
+package txtopener
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// language describes how the letters of a language are distributed and the single-byte charsets
+// it is usually written with, most common first.
+// letters lists the lowercase letters of the language, most frequent first. For languages
+// written with the Latin script only the letters outside ASCII are listed, along with the
+// non-ASCII punctuation their texts often use.
+// pairs lists, separated by spaces, the most common pairs of letters of the language with
+// at least one of them in letters
+type language struct {
+	code     string
+	latin    bool
+	letters  string
+	pairs    string
+	charsets []string
+}
+
+// fallbackCharset is the charset of the Western European languages, those covered by the
+// fallback encoding. Their models are the reference the others are rated against, and are never claimed
+const fallbackCharset = "windows-1252"
+
+// languages is the set of models of the single-byte detector
+var languages = []language{
+	{code: "es", latin: true, letters: "áéóíñúü¿¡«»", pairs: "ón ió ía ña ño ár ás án ér és úa", charsets: []string{"windows-1252"}},
+	{code: "fr", latin: true, letters: "éèàêçôîùûëâïœ«»ÿ", pairs: "té dé lé ré ée és ét èr ès ça ço ôt êt êm", charsets: []string{"windows-1252"}},
+	{code: "de", latin: true, letters: "äüößé„“«»", pairs: "ün ür üb üc üh än är äu äh äc ät ön ör ös öh öl ße ßt iß uß aß", charsets: []string{"windows-1252"}},
+	{code: "pt", latin: true, letters: "ãçéáóêíõúâôàü«»", pairs: "çã ão ãe õe çõ ác ém ên ív ís ót", charsets: []string{"windows-1252"}},
+	{code: "it", latin: true, letters: "èàéùòìó«»", pairs: "tà tù iù ià iò rò ìa", charsets: []string{"windows-1252"}},
+	{code: "nl", latin: true, letters: "ëéïèöüá", pairs: "ië ëe ée", charsets: []string{"windows-1252"}},
+	{code: "da", latin: true, letters: "øæåéó«»", pairs: "øn ør æn år ån ød ær", charsets: []string{"windows-1252"}},
+	{code: "sv", latin: true, letters: "äöåéü", pairs: "ör är än åt ån ös äl åg", charsets: []string{"windows-1252"}},
+	{code: "is", latin: true, letters: "ðáéíóþæöúý", pairs: "ði ðu ðr ög óð ár ír ás", charsets: []string{"windows-1252"}},
+	{code: "ca", latin: true, letters: "àèéíòóúçï·", pairs: "ió ià ès èn ós ça", charsets: []string{"windows-1252"}},
+	{code: "el", letters: "αοετινσηρκπυμλάδγέίόςωύήχθφβξζψώϊϋΐΰ", pairs: "ου το αι αν να τη ει ερ ην ον ατ ια στ ης ετ τα πο ντ απ κα με σε ρο", charsets: []string{"windows-1253", "iso-8859-7"}},
+	{code: "pl", latin: true, letters: "łęąóżśćńź", pairs: "ię ią ać ść łu ło ła ól ży że ąc ęd ńs", charsets: []string{"windows-1250", "iso-8859-2"}},
+	{code: "cs", latin: true, letters: "íáéěřýžčšůúňťďó", pairs: "ní ří ře ěj ší ží čn ýc ům ků", charsets: []string{"windows-1250", "iso-8859-2"}},
+	{code: "sk", latin: true, letters: "áíéýčžšúľťňôäďŕĺó", pairs: "ní čn šk ľa ľn ôs áv ýc", charsets: []string{"windows-1250", "iso-8859-2"}},
+	{code: "hu", latin: true, letters: "éáóöőíüúű", pairs: "és ől ég ét ár ál ün ző ős ék", charsets: []string{"windows-1250", "iso-8859-2"}},
+	{code: "hr", latin: true, letters: "čšžćđ", pairs: "či ša že ći đa", charsets: []string{"windows-1250", "iso-8859-2"}},
+	{code: "lt", latin: true, letters: "ėšįųūąžčę„“", pairs: "ės ių ąs ži ši ūr ėj ųs ūs iš", charsets: []string{"windows-1257", "iso-8859-13", "iso-8859-4"}},
+	{code: "lv", latin: true, letters: "āīēšūņļķčžģ„“", pairs: "ās ām ēj īj ūs ši ņa ļa ķi ēt", charsets: []string{"windows-1257", "iso-8859-13", "iso-8859-4"}},
+	{code: "et", latin: true, letters: "äõüöšž„“", pairs: "äi õi õp ün öö ää üt ša", charsets: []string{"windows-1257", "iso-8859-13", "iso-8859-4"}},
+	{code: "se", latin: true, letters: "ášččđŋžŧ", pairs: "áš ái čč đi ŋa", charsets: []string{"iso-8859-10", "iso-8859-4"}},
+	{code: "ru", letters: "оеаинтсрвлкмдпуяызьбгчйхжшюцщэфъё", pairs: "ст но то на ен ов ни ра во ко ал пр ер ть ре ли не ан ол по ет од го ос ор ка та ат ро ел ва ин", charsets: []string{"windows-1251", "koi8-r", "iso-8859-5", "ibm866"}},
+	{code: "uk", letters: "оанівритеслкудмпзяьгбчхцжйшюєїщфґ", pairs: "на ра ли ні ко пр ти но по ан ар ен во ав ст ов ер ня ри ви ий ід ів ре ро", charsets: []string{"windows-1251", "koi8-u", "iso-8859-5", "ibm866"}},
+	{code: "be", letters: "аоыніэрвлксдтмпзуяйеьгбчцшжхёюўф", pairs: "на ар ра не ст ка ла ал ан ць ых ці ві", charsets: []string{"windows-1251", "iso-8859-5", "ibm866"}},
+	{code: "bg", letters: "аоеинтрсвлкдпмзяъугбчщжйцшхюфь", pairs: "на та ст то не ни ра ре ен те ат ко по ри ва ли от но ов пр", charsets: []string{"windows-1251", "iso-8859-5"}},
+	{code: "sr", letters: "аоиенрсјтвдклупмзгбчшцћжхњљфђџ", pairs: "је на ст ра ко но ни пр ти ен ва ог", charsets: []string{"windows-1251", "iso-8859-5"}},
+	{code: "mk", letters: "аеоинтрсвкдлпмзјугбцчшњжѓфхќљѕџ", pairs: "на та ст ра ни от се ко пр ов ки", charsets: []string{"windows-1251", "iso-8859-5"}},
+	{code: "tr", latin: true, letters: "ışüçğöİâî", pairs: "ır ın ış şi çi ği ül ün ğı öy üz", charsets: []string{"windows-1254", "iso-8859-9"}},
+	{code: "vi", latin: true, letters: "ưđàơếạảâáềờộốôấợìữịọíệựýửớởêủểầậặồẳẩóằứãẹúùòõỏũụĩỉỳỷỹỵắẵẻẽễổỗỡừăéè", charsets: []string{"viscii"}},
+	{code: "ta", letters: "ுிதமபரகலறவயனசாளஅடநைேஉொோஎஇணஙூெஒழஸீஆஜஷஹஞஈஊஏஐஓஔஃ", charsets: []string{"tscii"}},
+	{code: "hy", letters: "անրեիոկմսւտլհդվգյպբզծցշչխթքձժըփռօֆճջղէ", charsets: []string{"armscii-8"}},
+	{code: "ka", letters: "აიესრმოლნბდვგხთუტყცშქკპზძწჩჭღჯფჰჟ", charsets: []string{"georgian-academy", "georgian-ps"}},
+	{code: "he", letters: "יוהלמארתבנשעכדקחםפסןזגטצךףץ", pairs: "ים ות של אל וה הא לה יה תי ני", charsets: []string{"windows-1255", "iso-8859-8-i"}},
+	{code: "ar", letters: "الیيمونهرتبةعدسفكقحجشطصىخثزضغذئءأإآؤظکگپچژ", pairs: "ال لا من في ان ها ية ين لم ما", charsets: []string{"windows-1256", "iso-8859-6"}},
+}
+
+// scores of the high bytes that aren't letters of the language being evaluated
+const (
+	foreignLetter = -9.0
+	symbol        = -6.0
+	undefined     = -12.0
+	// mixedScript is added for every letter of a non-Latin script glued to an ASCII letter,
+	// standing alone or capital after a small one, and for every non-ASCII letter of a Latin
+	// language standing alone or inside a run of three or more of them
+	mixedScript = -4.0
+	// commonPair is added for every letter making one of the common pairs of its language
+	// with a neighbour
+	commonPair = 1.5
+	// claimMargin is how much better than the fallback a candidate has to score to be claimed
+	claimMargin = 2.0
+	// minLetters is how many bytes have to decode to letters of its language for a candidate
+	// to be claimed, so that a few symbols don't tell a charset
+	minLetters = 3
+)
+
+// symbolLetters are the letters to Unicode that texts use as symbols: the ordinal indicators
+// and the micro sign
+const symbolLetters = "ªºµ"
+
+// sbcsCandidate is a charset together with the model of one language written with it
+type sbcsCandidate struct {
+	lang   string
+	name   string
+	e      encoding.Encoding
+	latin  bool
+	runes  [256]rune
+	score  [256]float64
+	letter [256]bool
+	upper  [256]bool
+	// native tells the bytes decoded to the letters of the language
+	native [256]bool
+	// lower are the runes the bytes decode to in lowercase, for the lookups in pairs
+	lower [256]rune
+	pairs map[[2]rune]bool
+}
+
+var (
+	sbcsOnce       sync.Once
+	sbcsCandidates []*sbcsCandidate
+)
+
+// candidates builds, once, the byte scores of every language and charset pair
+func candidates() []*sbcsCandidate {
+	sbcsOnce.Do(func() {
+		for _, lang := range languages {
+			logp := make(map[rune]float64)
+			for k, r := range []rune(lang.letters) {
+				logp[r] = rankLogProb(k)
+			}
+			pairs := make(map[[2]rune]bool)
+			for _, pair := range strings.Fields(lang.pairs) {
+				r := []rune(pair)
+				pairs[[2]rune{r[0], r[1]}] = true
+			}
+			seen := make(map[string]bool)
+
+			for _, cs := range lang.charsets {
+				e, name, err := lookup(cs)
+				if err != nil || seen[name] {
+					// WHATWG makes some labels, like iso-8859-9, aliases of another charset
+					continue
+				}
+				seen[name] = true
+				c := &sbcsCandidate{lang: lang.code, name: name, e: e, latin: lang.latin, pairs: pairs}
+				for b := 0; b < 0x80; b++ {
+					c.lower[b] = unicode.ToLower(rune(b))
+				}
+				for b := 0x80; b < 0x100; b++ {
+					r := decodeByte(e, byte(b))
+					c.lower[b] = unicode.ToLower(r)
+					c.runes[b] = r
+					switch {
+					case r == utf8.RuneError || r >= 0x80 && r < 0xa0:
+						c.score[b] = undefined
+					default:
+						c.letter[b] = unicode.IsLetter(r) && !strings.ContainsRune(symbolLetters, r)
+						c.upper[b] = unicode.IsUpper(r)
+						if p, ok := logp[r]; ok {
+							c.score[b], c.native[b] = p, c.letter[b]
+						} else if p, ok := logp[unicode.ToLower(r)]; ok {
+							c.score[b], c.native[b] = p, c.letter[b]
+						} else if c.letter[b] {
+							c.score[b] = foreignLetter
+						} else {
+							c.score[b] = symbol
+						}
+					}
+				}
+				sbcsCandidates = append(sbcsCandidates, c)
+			}
+		}
+	})
+	return sbcsCandidates
+}
+
+// rate returns how much better than the fallback fb the candidate fits the high bytes of
+// content at positions, given the rates of the candidate, own, and those of the fallback,
+// theirs, of each of them. The bytes both decode differently are what tells them apart; those
+// decoded alike count only against the candidate, when they are rarer in its language, unless
+// they aren't letters of the fallback's
+func (c *sbcsCandidate) rate(content []byte, positions []int, own, theirs []float64, fb *sbcsCandidate) float64 {
+	var s float64
+	for k, i := range positions {
+		b := content[i]
+		d := own[k] - theirs[k]
+		if c.runes[b] == fb.runes[b] && d > 0 && fb.score[b] > symbol {
+			continue
+		}
+		s += d
+	}
+	return s
+}
+
+// rates returns the rates of the bytes of content at positions
+func (c *sbcsCandidate) rates(content []byte, positions []int, s []float64) []float64 {
+	for k, i := range positions {
+		s[k] = c.rateAt(content, i)
+	}
+	return s
+}
+
+// rateAt rates the byte at content[i] taking into account its neighbours
+func (c *sbcsCandidate) rateAt(content []byte, i int) float64 {
+	b := content[i]
+	s := c.score[b]
+	if !c.letter[b] {
+		return s
+	}
+	prev, next := byte(' '), byte(' ')
+	if i > 0 {
+		prev = content[i-1]
+	}
+	if i+1 < len(content) {
+		next = content[i+1]
+	}
+	if c.latin {
+		isLetter := func(b byte) bool { return isASCIILetter(b) || b >= 0x80 && c.letter[b] }
+		if prev >= 0x80 && c.letter[prev] && next >= 0x80 && c.letter[next] || !isLetter(prev) && !isLetter(next) {
+			s += mixedScript
+		}
+	} else if isASCIILetter(prev) || isASCIILetter(next) || !c.isLetter(prev) && !c.isLetter(next) {
+		s += mixedScript
+	}
+	if !c.latin && c.upper[b] && c.isLetter(prev) && !c.upper[prev] {
+		// texts capitalize words, a capital after a small letter tells a charset with the cases swapped
+		s += mixedScript
+	}
+	if c.native[b] && len(c.pairs) > 0 && (c.pairs[[2]rune{c.lower[prev], c.lower[b]}] || c.pairs[[2]rune{c.lower[b], c.lower[next]}]) {
+		s += commonPair
+	}
+	return s
+}
+
+// isLetter tells whether b is a letter of the language of the candidate
+func (c *sbcsCandidate) isLetter(b byte) bool {
+	return b >= 0x80 && c.letter[b]
+}
+
+// decodeByte returns the rune b decodes to in the single-byte encoding e
+func decodeByte(e encoding.Encoding, b byte) rune {
+	if bd, ok := e.(byteDecoder); ok {
+		return bd.DecodeByte(b)
+	}
+	s, _ := e.NewDecoder().Bytes([]byte{b})
+	r, n := utf8.DecodeRune(s)
+	if n != len(s) {
+		return utf8.RuneError
+	}
+	return r
+}
+
+// rankLogProb returns the log-probability given to the k-th most frequent letter of a language.
+// It doesn't depend on how many letters the language has so that short lists don't get an edge
+func rankLogProb(k int) float64 {
+	const lambda, ranks = 5.0, 40
+	var total float64
+	for i := 0; i < ranks; i++ {
+		total += math.Exp(-float64(i) / lambda)
+	}
+	return -float64(k)/lambda - math.Log(total)
+}
+
+func isASCIILetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// detectSBCS rates content against the letter-frequency and letter-pair models of several
+// languages and returns the legacy single-byte charset it most likely uses. Nothing is claimed
+// when the content fits the Western European languages of the fallback encoding best, or when
+// fewer than minLetters of its bytes are letters of the language of the charset.
+// The charsets of the language lang, if not empty, are claimed as soon as they fit better than
+// the fallback and win over those of the other languages
+func detectSBCS(content []byte, lang string) (e encoding.Encoding, name string, confidence float64) {
+	var positions []int
+	for i, b := range content {
+		if b >= 0x80 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) == 0 {
+		return nil, "", 0
+	}
+
+	// the fallbacks are rated once, the candidates against each of them
+	var fallbacks, cs []*sbcsCandidate
+	var theirs [][]float64
+	for _, c := range candidates() {
+		if c.name == fallbackCharset {
+			fallbacks = append(fallbacks, c)
+			theirs = append(theirs, c.rates(content, positions, make([]float64, len(positions))))
+		} else {
+			cs = append(cs, c)
+		}
+	}
+
+	var best *sbcsCandidate
+	var diff float64
+	own := make([]float64, len(positions))
+	for _, c := range cs {
+		hinted := c.lang == lang
+		letters := 0
+		for _, i := range positions {
+			if c.native[content[i]] {
+				letters++
+			}
+		}
+		if letters == 0 || !hinted && letters < minLetters {
+			continue
+		}
+		c.rates(content, positions, own)
+		d := math.Inf(1)
+		for j, fb := range fallbacks {
+			d = math.Min(d, c.rate(content, positions, own, theirs[j], fb))
+		}
+		switch {
+		case hinted && d <= 0, !hinted && d < claimMargin:
+		case best == nil, hinted && best.lang != lang, hinted == (best.lang == lang) && d > diff:
+			best, diff = c, d
+		}
+	}
+	if best == nil {
+		return nil, "", 0
+	}
+	if best.lang == "he" && isVisualHebrew(content) {
+		e, name, _ := lookup("iso-8859-8")
+		return e, name, diff / (diff + 10)
+	}
+	return best.e, best.name, diff / (diff + 10)
+}
@@ -0,0 +1,227 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// samples of text in several languages used to check the single-byte detector
+var (
+	greekSample      = "Η γρήγορη καφέ αλεπού πηδάει πάνω από το τεμπέλικο σκυλί. Άλλωστε, η ελληνική γλώσσα έχει πλούσια ιστορία."
+	polishSample     = "Zażółć gęślą jaźń. Pchnąć w tę łódź jeża lub ośm skrzyń fig. Mój brat mieszka w Łodzi."
+	czechSample      = "Příliš žluťoučký kůň úpěl ďábelské ódy. Všichni lidé se rodí svobodní a sobě rovní."
+	hungarianSample  = "Árvíztűrő tükörfúrógép. Öt szép szűz lány őrült írót nyúz, és egyenlő méltósága van."
+	turkishSample    = "Pijamalı hasta yağız şoföre çabucak güvendi. Türkiye'nin başkenti Ankara'dır ve İstanbul en büyük şehridir."
+	lithuanianSample = "Visi žmonės gimsta laisvi ir lygūs savo orumu ir teisėmis. Jiems suteiktas protas ir sąžinė, todėl jie turi elgtis vienas kito atžvilgiu kaip broliai."
+	latvianSample    = "Visi cilvēki piedzimst brīvi un vienlīdzīgi savā pašcieņā un tiesībās. Viņi ir apveltīti ar saprātu un sirdsapziņu, un viņiem jāizturas citam pret citu brālības garā."
+	russianSample    = "Съешь же ещё этих мягких французских булок, да выпей чаю. Все люди рождаются свободными и равными в своём достоинстве и правах."
+	ukrainianSample  = "Усі люди народжуються вільними і рівними у своїй гідності та правах. Вони наділені розумом і совістю і повинні діяти у відношенні один до одного в дусі братерства."
+	westernSample    = "Le coeur déçu mais l'âme plutôt naïve, Louÿs rêva de crapaüter en canoë au delà des îles. El pingüino Wenceslao hizo kilómetros bajo exhaustiva lluvia y frío. Zwölf Boxkämpfer jagen Viktor quer über den großen Sylter Deich."
+)
+
+func TestDetectSBCS(t *testing.T) {
+	var tests = []struct {
+		text string
+		enc  *charmap.Charmap
+	}{
+		{greekSample, charmap.Windows1253},
+		{greekSample, charmap.ISO8859_7},
+		{polishSample, charmap.Windows1250},
+		{polishSample, charmap.ISO8859_2},
+		{czechSample, charmap.Windows1250},
+		{czechSample, charmap.ISO8859_2},
+		{hungarianSample, charmap.Windows1250},
+		{hungarianSample, charmap.ISO8859_2},
+		{turkishSample, charmap.Windows1254},
+		{turkishSample, charmap.ISO8859_9},
+		{lithuanianSample, charmap.Windows1257},
+		{lithuanianSample, charmap.ISO8859_13},
+		{latvianSample, charmap.Windows1257},
+		{latvianSample, charmap.ISO8859_4},
+		{russianSample, charmap.Windows1251},
+		{russianSample, charmap.KOI8R},
+		{russianSample, charmap.ISO8859_5},
+		{russianSample, charmap.CodePage866},
+		{"Добрый день", charmap.KOI8R},
+		{"Это тест", charmap.KOI8R},
+		{"Он сказал: да.", charmap.KOI8R},
+		{"Он сказал: да.", charmap.ISO8859_5},
+		{"Файл не найден, повторите попытку", charmap.Windows1251},
+		{"ОШИБКА: файл не найден", charmap.KOI8R},
+		{ukrainianSample, charmap.Windows1251},
+		{ukrainianSample, charmap.KOI8U},
+		{westernSample, charmap.Windows1252},
+		{westernSample, charmap.ISO8859_1},
+		{"café", charmap.ISO8859_1},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.enc.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		got, err := ioutil.ReadAll(NewReader(strings.NewReader(feed)))
+		if err != nil {
+			t.Errorf("error en ReadAll: %v", err)
+		}
+		if string(got) != tt.text {
+			t.Errorf("%d. %s -> got: %s - expected: %s", i, tt.enc, got, tt.text)
+		}
+	}
+}
+
+var (
+	hebrewSample = "שלום עולם, זהו טקסט בעברית שנכתב בקידוד ישן. הספרים נמצאים על המדף הגדול בבית הספר."
+	arabicSample = "مرحبا بالعالم، هذا نص باللغة العربية مكتوب بترميز قديم. الكتاب موجود على الطاولة في المدرسة."
+)
+
+func TestDetectHebrewArabic(t *testing.T) {
+	var tests = []struct {
+		text     string
+		enc      *charmap.Charmap
+		expected string
+	}{
+		{hebrewSample, charmap.Windows1255, "windows-1255"},
+		{arabicSample, charmap.Windows1256, "windows-1256"},
+		{arabicSample, charmap.ISO8859_6, "iso-8859-6"},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.enc.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		if _, name, _ := determineEncoding([]byte(feed), newOptions(nil)); name != tt.expected {
+			t.Errorf("%d. %s -> got: %s - expected: %s", i, tt.enc, name, tt.expected)
+		}
+	}
+}
+
+func TestVisualToLogical(t *testing.T) {
+	logical := "שלום עולם (abc 123) סוף\nשורה שנייה"
+	var visual []string
+	for _, line := range strings.Split(logical, "\n") {
+		visual = append(visual, string(reorderVisual([]byte(line))))
+	}
+	feed, err := charmap.ISO8859_8.NewEncoder().String(strings.Join(visual, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, name, _ := determineEncoding([]byte(feed), newOptions(nil)); name != "iso-8859-8" {
+		t.Errorf("visual order not detected, got: %s", name)
+	}
+	r, err := NewReaderOpts(strings.NewReader(feed), WithVisualToLogical())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Errorf("error en ReadAll: %v", err)
+	}
+	if string(got) != logical {
+		t.Errorf("got: %s - expected: %s", got, logical)
+	}
+}
+
+func TestLanguageHint(t *testing.T) {
+	var tests = []struct {
+		text     string
+		enc      encoding.Encoding
+		hint     string
+		expected string
+	}{
+		{"Да", charmap.KOI8R, "ru", "koi8-r"},
+		{"Ok да", charmap.KOI8R, "ru-RU", "koi8-r"},
+		{"Привет", charmap.Windows1251, "ru", "windows-1251"},
+		{"東京", japanese.ShiftJIS, "ja", "shift_jis"},
+		{"東京", japanese.EUCJP, "ja_JP", "euc-jp"},
+		{"café", charmap.ISO8859_1, "ru", "ISO 8859-1"},
+		{"café", charmap.ISO8859_1, "fr", "ISO 8859-1"},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.enc.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		_, name, _ := DetectEncoding([]byte(feed), "", WithLanguageHint(tt.hint))
+		if name != tt.expected {
+			t.Errorf("%d. feeded: %q, %s -> got: %s - expected: %s", i, tt.text, tt.hint, name, tt.expected)
+		}
+	}
+}
+
+func TestDetectSBCSSymbols(t *testing.T) {
+	var tests = []string{
+		"±5 µm",
+		"±5 µm ° ©2020 ®",
+		"Temp: 20°C ± 0,5° · © ACME ® ½ ¼ ¾ § 3 ¶ 2 µs",
+		"Precio: 12,50 - ¡oferta! «nuevo» ±1",
+		"ª º ¹ ² ³ × ÷ ¬ ¦",
+	}
+
+	for i, tt := range tests {
+		feed, err := charmap.ISO8859_1.NewEncoder().String(tt)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		if _, name, _ := DetectEncoding([]byte(feed), ""); name != "ISO 8859-1" {
+			t.Errorf("%d. feeded: %q -> got: %s - expected: ISO 8859-1", i, tt, name)
+		}
+	}
+}
+
+func TestWithoutModels(t *testing.T) {
+	var tests = []struct {
+		text     string
+		enc      encoding.Encoding
+		opts     []Option
+		expected string
+	}{
+		{russianSample, charmap.Windows1251, nil, "windows-1251"},
+		{russianSample, charmap.Windows1251, []Option{WithoutModels()}, "ISO 8859-1"},
+		{greekSample, charmap.ISO8859_7, []Option{WithoutModels(), WithFallback("windows-1252")}, "windows-1252"},
+		{"東京の天気は晴れです", japanese.ShiftJIS, []Option{WithoutModels()}, "ISO 8859-1"},
+		{"Да", charmap.KOI8R, []Option{WithoutModels(), WithLanguageHint("ru")}, "ISO 8859-1"},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.enc.NewEncoder().String(tt.text)
+		if err != nil {
+			t.Fatalf("%d. %v", i, err)
+		}
+		if _, name, _ := DetectEncoding([]byte(feed), "", tt.opts...); name != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %s - expected: %s", i, tt.text, name, tt.expected)
+		}
+	}
+}
+
+func TestCommonPairs(t *testing.T) {
+	var ru *sbcsCandidate
+	for _, c := range candidates() {
+		if c.lang == "ru" && c.name == "windows-1251" {
+			ru = c
+		}
+	}
+	var tests = []struct {
+		common, rare string
+	}{
+		{"на", "нщ"},
+		{"ст", "сщ"},
+		{"по", "пэ"},
+	}
+
+	for i, tt := range tests {
+		common, _ := charmap.Windows1251.NewEncoder().Bytes([]byte(" " + tt.common + " "))
+		rare, _ := charmap.Windows1251.NewEncoder().Bytes([]byte(" " + tt.rare + " "))
+		if got, other := ru.rateAt(common, 1), ru.rateAt(rare, 1); got <= other {
+			t.Errorf("%d. %s rates %g, not better than %s: %g", i, tt.common, got, tt.rare, other)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"regexp"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Match is a pattern match found in decoded text
+type Match struct {
+	// Path is the name of the file holding the match, as given to fs.WalkDir. Grep leaves it empty
+	Path string
+	// Line is the 1-based number of the line holding the match
+	Line int
+	// Text is the decoded line, without its line ending
+	Text string
+	// Offset is the position in the original, undecoded, input where the match starts
+	Offset int64
+}
+
+// Grep decodes r to UTF-8 and calls fn for every match of pattern, reporting the offset
+// of each match in the original bytes of r. Matches don't span lines.
+// Grep stops and returns the error if fn returns one
+func Grep(r io.Reader, pattern *regexp.Regexp, fn func(Match) error) error {
+	o := newOptions(nil)
+	preview := make([]byte, o.preview())
+	n, err := io.ReadFull(r, preview)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		preview = preview[:n]
+	case err != nil:
+		return err
+	}
+	e, _, _ := determineEncoding(preview, o)
+
+	lines := lineSplitter{fn: func(num int, line []byte, offs []int64, end int64) error {
+		for _, loc := range pattern.FindAllIndex(line, -1) {
+			m := Match{Line: num, Text: string(line), Offset: end}
+			if loc[0] < len(offs) {
+				m.Offset = offs[loc[0]]
+			}
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+	if err := decodeOffsets(io.MultiReader(bytes.NewReader(preview), r), e, lines.write); err != nil {
+		return err
+	}
+	return lines.flush()
+}
+
+// Search walks fsys and calls fn for every match of pattern found in its regular files,
+// decoding each of them as Grep does.
+// Files that can't be read don't stop the walk; their errors are joined and returned once it
+// completes. An error returned by fn stops the search immediately and is returned as is
+func Search(fsys fs.FS, pattern *regexp.Regexp, fn func(Match) error) error {
+	var errs []error
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		f, err := fsys.Open(path)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		defer f.Close()
+
+		err = Grep(f, pattern, func(m Match) error {
+			m.Path = path
+			if err := fn(m); err != nil {
+				return stopSearch{err}
+			}
+			return nil
+		})
+		if stop, ok := err.(stopSearch); ok {
+			return stop.err
+		}
+		if err != nil {
+			errs = append(errs, &fs.PathError{Op: "read", Path: path, Err: err})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// stopSearch tells apart the errors returned by the callback of Search from the read errors
+type stopSearch struct {
+	err error
+}
+
+func (s stopSearch) Error() string {
+	return s.err.Error()
+}
+
+// decodeOffsets decodes r with e calling fn with every piece of decoded text together with
+// the offset in r of the bytes it was decoded from.
+// The decoder is fed the shortest input it can make progress with, so every piece comes from
+// a single character of the source
+func decodeOffsets(r io.Reader, e encoding.Encoding, fn func(text []byte, offset int64) error) error {
+	t := e.NewDecoder()
+	chunk := make([]byte, 4096)
+	dst := make([]byte, 64)
+	var pending []byte
+	var offset int64
+	eof, starved := false, true
+	for {
+		for !eof && (starved || len(pending) < 16) {
+			n, err := r.Read(chunk)
+			pending = append(pending, chunk[:n]...)
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+			starved = false
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		starved = true
+		for n := 1; n <= len(pending); n++ {
+			atEOF := eof && n == len(pending)
+			nDst, nSrc, err := t.Transform(dst, pending[:n], atEOF)
+			if err != nil && err != transform.ErrShortSrc {
+				return err
+			}
+			if nDst > 0 {
+				if err := fn(dst[:nDst], offset); err != nil {
+					return err
+				}
+			}
+			if nSrc == 0 {
+				if atEOF {
+					return nil
+				}
+				continue
+			}
+			offset += int64(nSrc)
+			pending = pending[nSrc:]
+			starved = false
+			break
+		}
+	}
+}
+
+// lineSplitter gathers the pieces produced by decodeOffsets into lines, keeping the
+// original offset of every byte of the line, and hands them to fn
+type lineSplitter struct {
+	fn      func(num int, line []byte, offs []int64, end int64) error
+	num     int
+	started bool
+	line    []byte
+	offs    []int64
+	end     int64
+}
+
+func (l *lineSplitter) write(text []byte, offset int64) error {
+	if !l.started {
+		// the BOM, if any, isn't part of the text
+		text = bytes.TrimPrefix(text, []byte{0xef, 0xbb, 0xbf})
+		l.started = true
+	}
+	l.end = offset
+	for _, c := range text {
+		if c == '\n' {
+			if err := l.emit(); err != nil {
+				return err
+			}
+			continue
+		}
+		l.line = append(l.line, c)
+		l.offs = append(l.offs, offset)
+	}
+	return nil
+}
+
+func (l *lineSplitter) emit() error {
+	l.num++
+	line, offs := l.line, l.offs
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line, offs = line[:n-1], offs[:n-1]
+	}
+	err := l.fn(l.num, line, offs, l.end)
+	l.line, l.offs = l.line[:0], l.offs[:0]
+	return err
+}
+
+func (l *lineSplitter) flush() error {
+	if len(l.line) == 0 {
+		return nil
+	}
+	return l.emit()
+}
@@ -0,0 +1,69 @@
+package txtopener
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGrep(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		pattern  string
+		expected []Match
+	}{
+		{"", "a", nil},
+		{"abc\ndef\n", "e", []Match{{"", 2, "def", 5}}},
+		{"pingüino\r\nça va\r\n", "ino|va", []Match{{"", 1, "pingüino", 6}, {"", 2, "ça va", 15}}},
+		{"fa\xe7ade\nfa\xe7ade", "ade", []Match{{"", 1, "façade", 3}, {"", 2, "façade", 10}}},
+		{string(utf8bom) + "pingüino", "ping", []Match{{"", 1, "pingüino", 3}}},
+		{string(utf16lebom) + "a\x00\n\x00\xfc\x00b\x00", "b", []Match{{"", 2, "üb", 8}}},
+	}
+
+	for i, tt := range tests {
+		var got []Match
+		err := Grep(strings.NewReader(tt.feed), regexp.MustCompile(tt.pattern), func(m Match) error {
+			got = append(got, m)
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%d. error en Grep: %v", i, err)
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("%d. feeded: %q -> got: %v - expected: %v", i, tt.feed, got, tt.expected)
+			continue
+		}
+		for j := range got {
+			if got[j] != tt.expected[j] {
+				t.Errorf("%d. feeded: %q -> got: %v - expected: %v", i, tt.feed, got[j], tt.expected[j])
+			}
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.txt":     {Data: []byte("pingüino\n")},
+		"dir/b.txt": {Data: append(append([]byte{}, utf16lebom...), "i\x00n\x00o\x00"...)},
+		"dir/c.txt": {Data: []byte("nothing here")},
+	}
+
+	var got []Match
+	err := Search(fsys, regexp.MustCompile("ino|no$"), func(m Match) error {
+		got = append(got, m)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("error en Search: %v", err)
+	}
+	expected := []Match{{"a.txt", 1, "pingüino", 6}, {"dir/b.txt", 1, "ino", 2}}
+	if len(got) != len(expected) {
+		t.Fatalf("got: %v - expected: %v", got, expected)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("%d. got: %v - expected: %v", i, got[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// WithReadSeeker makes the reader read whole the output of the inputs that decode to at most
+// maxSize bytes and return it as a *bytes.Reader, an io.ReadSeeker, so that parsers needing to
+// seek or to retry can use it directly. Bigger inputs are still streamed:
+//
+//	r, err := txtopener.NewReaderOpts(f, txtopener.WithReadSeeker(1<<20))
+//	if rs, ok := r.(io.ReadSeeker); ok {
+//		...
+//	}
+func WithReadSeeker(maxSize int) Option {
+	return func(o *options) {
+		o.seekable = maxSize
+	}
+}
+
+// bufferSmall returns the content of r in a *bytes.Reader if it is at most max bytes long
+// and a reader of it all otherwise
+func bufferSmall(r io.Reader, max int) (io.Reader, error) {
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, r, int64(max)+1)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if n <= int64(max) {
+		return bytes.NewReader(buf.Bytes()), nil
+	}
+	return io.MultiReader(&buf, r), nil
+}
+
+// seekingResult is decodingResult for sources which can seek: the reader returned is an
+// io.ReadSeeker too
+func seekingResult(src io.ReadSeeker, o *options) (io.Reader, Result, error) {
+	start, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return streamingResult(src, o)
+	}
+	r, res, err := streamingResult(src, o)
+	if err != nil {
+		return nil, Result{}, err
+	}
+	return &seekReader{src: src, start: start, o: o, r: r}, res, nil
+}
+
+// errSeekEnd is returned seeking relative to the end of the output, whose size isn't known
+var errSeekEnd = errors.New("txtopener: seek relative to the end not supported")
+
+// errNegativeSeek is returned seeking before the start of the output
+var errNegativeSeek = errors.New("txtopener: negative position")
+
+// seekReader is the output of the decoding of src, which started at start. Its offsets count the
+// decoded bytes: seeking backwards decodes src again from start, and seeking forwards
+// decodes and discards what is skipped
+type seekReader struct {
+	src   io.ReadSeeker
+	start int64
+	o     *options
+	r     io.Reader
+	pos   int64
+}
+
+func (s *seekReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.pos += int64(n)
+	return n, err
+}
+
+// Seek sets the offset of the next Read in the decoded output. Seeking relative to
+// io.SeekEnd isn't supported
+func (s *seekReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	default:
+		return s.pos, errSeekEnd
+	}
+	if offset < 0 {
+		return s.pos, errNegativeSeek
+	}
+
+	if offset < s.pos {
+		if _, err := s.src.Seek(s.start, io.SeekStart); err != nil {
+			return s.pos, err
+		}
+		// the input was already counted by the stats
+		o := *s.o
+		o.stats = nil
+		r, _, err := streamingResult(s.src, &o)
+		if err != nil {
+			return s.pos, err
+		}
+		s.r, s.pos = r, 0
+	}
+	n, err := io.CopyN(io.Discard, s.r, offset-s.pos)
+	s.pos += n
+	if err != nil && err != io.EOF {
+		return s.pos, err
+	}
+	return s.pos, nil
+}
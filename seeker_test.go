@@ -0,0 +1,113 @@
+package txtopener
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithReadSeeker(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		max      int
+		seekable bool
+		expected string
+	}{
+		{"caf\xe9", 16, true, "café"},
+		{"\xef\xbb\xbfaño", 4, true, "año"},
+		{"", 16, true, ""},
+		{"caf\xe9 con leche", 8, false, "café con leche"},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderOpts(struct{ io.Reader }{strings.NewReader(tt.feed)}, WithReadSeeker(tt.max))
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		rs, ok := r.(io.ReadSeeker)
+		if ok != tt.seekable {
+			t.Errorf("%d. feeded: %q -> seekable: %v - expected: %v", i, tt.feed, ok, tt.seekable)
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil || string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q", i, tt.feed, got, err, tt.expected)
+		}
+		if ok {
+			if _, err := rs.Seek(0, io.SeekStart); err != nil {
+				t.Errorf("%d. error en Seek: %v", i, err)
+			}
+			if again, _ := ioutil.ReadAll(rs); string(again) != tt.expected {
+				t.Errorf("%d. after Seek -> got: %q - expected: %q", i, again, tt.expected)
+			}
+		}
+	}
+}
+
+func TestSeekSource(t *testing.T) {
+	feed := "\xef\xbb\xbfcaf\xc3\xa9 " + strings.Repeat("con leche ", 2000)
+	expected := "café " + strings.Repeat("con leche ", 2000)
+
+	name := filepath.Join(t.TempDir(), "seek.txt")
+	if err := os.WriteFile(name, []byte(feed), 0666); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		t.Fatalf("the reader of a file can't seek")
+	}
+
+	var tests = []struct {
+		offset   int64
+		whence   int
+		expected int64
+	}{
+		{0, io.SeekStart, 0},
+		{3, io.SeekStart, 3},
+		{10, io.SeekCurrent, 110},
+		{2, io.SeekStart, 2},
+		{int64(len(expected)) + 5, io.SeekStart, int64(len(expected))},
+	}
+	for i, tt := range tests {
+		if _, err := ioutil.ReadAll(io.LimitReader(rs, 100)); err != nil {
+			t.Fatal(err)
+		}
+		pos, err := rs.Seek(tt.offset, tt.whence)
+		if err != nil || pos != tt.expected {
+			t.Errorf("%d. Seek(%d, %d) -> got: %d, %v - expected: %d", i, tt.offset, tt.whence, pos, err, tt.expected)
+			continue
+		}
+		got, _ := ioutil.ReadAll(rs)
+		if string(got) != expected[pos:] {
+			t.Errorf("%d. after Seek(%d, %d) -> got %d bytes - expected: %d", i, tt.offset, tt.whence, len(got), len(expected)-int(pos))
+		}
+		rs.Seek(0, io.SeekStart)
+	}
+
+	if _, err := rs.Seek(0, io.SeekEnd); err == nil {
+		t.Errorf("expected an error seeking from the end")
+	}
+	if _, err := rs.Seek(-1, io.SeekStart); err == nil {
+		t.Errorf("expected an error seeking before the start")
+	}
+
+	// the decoding starts where the source was
+	src := strings.NewReader("skipped caf\xe9")
+	src.Seek(8, io.SeekStart)
+	r := NewReader(src)
+	ioutil.ReadAll(r)
+	if _, err := r.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("error en Seek: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "café" {
+		t.Errorf("got: %q - expected: %q", got, "café")
+	}
+}
@@ -0,0 +1,163 @@
+package txtopener
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// The split functions below are meant for a bufio.Scanner reading the output of the readers of
+// the package. Unlike those of bufio they skip the U+FEFF (the BOM, or the zero width no-break
+// space it stands for) left behind by concatenated files, and they return a truncated sequence
+// at the end of the input as one U+FFFD
+
+// zwnbsp is the zero width no-break space, the code point of the BOM
+const zwnbsp = '\ufeff'
+
+// zwj is the zero width joiner, which glues emoji together
+const zwj = '\u200d'
+
+// decodeRune returns the first rune of data and its size, asking for more data if it is
+// incomplete and at EOF taking it for a U+FFFD
+func decodeRune(data []byte, atEOF bool) (r rune, size int, more bool) {
+	if !atEOF && !utf8.FullRune(data) {
+		return 0, 0, true
+	}
+	r, size = utf8.DecodeRune(data)
+	return r, size, false
+}
+
+// ScanRunes is a bufio.SplitFunc returning each rune as a token
+func ScanRunes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for advance < len(data) {
+		r, size, more := decodeRune(data[advance:], atEOF)
+		if more {
+			return advance, nil, nil
+		}
+		switch {
+		case r == zwnbsp:
+			advance += size
+		case r == utf8.RuneError:
+			return advance + size, replacementChar, nil
+		default:
+			return advance + size, data[advance : advance+size], nil
+		}
+	}
+	return advance, nil, nil
+}
+
+// ScanGraphemes is a bufio.SplitFunc returning each grapheme cluster, what users see as a
+// character, as a token. It approximates the extended grapheme clusters of UAX #29: a rune
+// together with the combining marks, variation selectors, emoji modifiers and zero width
+// joiner sequences following it, a pair of regional indicators (a flag) or a CR LF
+func ScanGraphemes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	var start int
+	for {
+		if start >= len(data) {
+			return start, nil, nil
+		}
+		r, size, more := decodeRune(data[start:], atEOF)
+		if more {
+			return start, nil, nil
+		}
+		if r != zwnbsp {
+			break
+		}
+		start += size
+	}
+
+	first, end, _ := decodeRune(data[start:], atEOF)
+	end += start
+	if first == utf8.RuneError {
+		return end, replacementChar, nil
+	}
+	prev := first
+	for end < len(data) {
+		r, size, more := decodeRune(data[end:], atEOF)
+		if more {
+			return start, nil, nil
+		}
+		joins := false
+		switch {
+		case prev == '\r':
+			joins = r == '\n'
+		case prev == '\n':
+		case prev == zwj:
+			joins = !unicode.IsSpace(r)
+		case isRegionalIndicator(prev) && isRegionalIndicator(r):
+			// only in pairs
+			joins = end-start == utf8.RuneLen(prev)
+		default:
+			joins = extendsGrapheme(r)
+		}
+		if !joins {
+			return end, data[start:end], nil
+		}
+		prev = r
+		end += size
+	}
+	if !atEOF {
+		// the cluster may go on
+		return start, nil, nil
+	}
+	return end, data[start:end], nil
+}
+
+// extendsGrapheme tells whether r belongs to the grapheme cluster of the rune before it
+func extendsGrapheme(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) ||
+		r == zwj || r >= 0x1f3fb && r <= 0x1f3ff
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// ScanWords is a bufio.SplitFunc returning each word as a token. Unlike bufio.ScanWords,
+// which splits at spaces, words are runs of letters, digits, combining marks and connector
+// punctuation, which may hold single apostrophes and hyphens between letters, as in "don't" or
+// "co-op"; everything else is left out
+func ScanWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// skip what isn't part of a word
+	start := 0
+	for start < len(data) {
+		r, size, more := decodeRune(data[start:], atEOF)
+		if more {
+			return start, nil, nil
+		}
+		if isWordRune(r) {
+			break
+		}
+		start += size
+	}
+
+	end := start
+	for end < len(data) {
+		r, size, more := decodeRune(data[end:], atEOF)
+		if more {
+			return start, nil, nil
+		}
+		if isWordRune(r) {
+			end += size
+			continue
+		}
+		if (r == '\'' || r == '’' || r == '-') && end > start {
+			next, nsize, more := decodeRune(data[end+size:], atEOF)
+			if more {
+				return start, nil, nil
+			}
+			if nsize > 0 && unicode.IsLetter(next) {
+				end += size + nsize
+				continue
+			}
+		}
+		return end, data[start:end], nil
+	}
+	if !atEOF || end == start {
+		return start, nil, nil
+	}
+	return end, data[start:end], nil
+}
+
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.In(r, unicode.M, unicode.Pc))
+}
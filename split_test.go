@@ -0,0 +1,42 @@
+package txtopener
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSplitFuncs(t *testing.T) {
+	var tests = []struct {
+		split    bufio.SplitFunc
+		feed     string
+		expected []string
+	}{
+		{ScanRunes, "\ufeffaño\ufeff日", []string{"a", "ñ", "o", "日"}},
+		{ScanRunes, "ab\xe6\x97", []string{"a", "b", "�", "�"}},
+		{ScanGraphemes, "ñ", []string{"ñ"}},
+		{ScanGraphemes, "é\r\nx", []string{"é", "\r\n", "x"}},
+		{ScanGraphemes, "\U0001F1E6\U0001F1F7\U0001F1EA\U0001F1F8", []string{"\U0001F1E6\U0001F1F7", "\U0001F1EA\U0001F1F8"}},
+		{ScanGraphemes, "\U0001F468\u200d\U0001F469\u200d\U0001F467!", []string{"\U0001F468\u200d\U0001F469\u200d\U0001F467", "!"}},
+		{ScanGraphemes, "\U0001F44D\U0001F3FD\ufeff", []string{"\U0001F44D\U0001F3FD"}},
+		{ScanWords, "\ufeffDon't stop, co-op 42 años—snake_case 'quoted'", []string{"Don't", "stop", "co-op", "42", "años", "snake_case", "quoted"}},
+		{ScanWords, "naïve café", []string{"naïve", "café"}},
+	}
+
+	for i, tt := range tests {
+		s := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(tt.feed)))
+		s.Split(tt.split)
+		var got []string
+		for s.Scan() {
+			got = append(got, s.Text())
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("%d. error en Scan: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats collects the figures of every reader created with WithStats. It is safe for concurrent
+// use, so the readers of many goroutines can share one. The zero value is ready to use
+type Stats struct {
+	mu        sync.Mutex
+	readers   int64
+	encodings map[string]int64
+	evidence  map[Evidence]int64
+
+	bytes        atomic.Int64
+	replacements atomic.Int64
+}
+
+// StatsSnapshot holds the figures collected by a Stats up to a moment
+type StatsSnapshot struct {
+	// Readers is the number of readers that have reported
+	Readers int64
+	// Encodings counts the readers by the name of the encoding of their input
+	Encodings map[string]int64
+	// Evidence counts the readers by what decided the encoding of their input, which tells how
+	// many of them rely on a guess
+	Evidence map[Evidence]int64
+	// Bytes is the number of bytes of UTF-8 read from the readers
+	Bytes int64
+	// Replacements is the number of U+FFFD read from the readers, which mostly stand for
+	// input not valid in its encoding
+	Replacements int64
+}
+
+// WithStats makes the reader report to s the encoding it detects and the bytes and
+// replacement characters it returns
+func WithStats(s *Stats) Option {
+	return func(o *options) {
+		o.stats = s
+	}
+}
+
+// Snapshot returns the figures collected so far
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := StatsSnapshot{
+		Readers:      s.readers,
+		Encodings:    make(map[string]int64, len(s.encodings)),
+		Evidence:     make(map[Evidence]int64, len(s.evidence)),
+		Bytes:        s.bytes.Load(),
+		Replacements: s.replacements.Load(),
+	}
+	for name, n := range s.encodings {
+		snap.Encodings[name] = n
+	}
+	for ev, n := range s.evidence {
+		snap.Evidence[ev] = n
+	}
+	return snap
+}
+
+// reader counts a new reader whose input was detected as res tells and returns r wrapped to
+// report what it returns. A nil s returns r as it is
+func (s *Stats) reader(r io.Reader, res Result) io.Reader {
+	if s == nil {
+		return r
+	}
+	s.mu.Lock()
+	s.readers++
+	if s.encodings == nil {
+		s.encodings = map[string]int64{}
+		s.evidence = map[Evidence]int64{}
+	}
+	s.encodings[res.Encoding]++
+	s.evidence[res.Evidence]++
+	s.mu.Unlock()
+	return &statsReader{r: r, s: s}
+}
+
+// replacementChar is U+FFFD in UTF-8
+var replacementChar = []byte("�")
+
+// statsReader reports to s the bytes read from r
+type statsReader struct {
+	r io.Reader
+	s *Stats
+	// tail holds the last bytes returned, which may start a U+FFFD split across two reads
+	tail []byte
+}
+
+func (sr *statsReader) Read(p []byte) (int, error) {
+	n, err := sr.r.Read(p)
+	if n > 0 {
+		sr.s.bytes.Add(int64(n))
+		buf := append(sr.tail, p[:n]...)
+		if c := bytes.Count(buf, replacementChar); c > 0 {
+			sr.s.replacements.Add(int64(c))
+			buf = buf[bytes.LastIndex(buf, replacementChar)+len(replacementChar):]
+		}
+		if len(buf) > len(replacementChar)-1 {
+			buf = buf[len(buf)-len(replacementChar)+1:]
+		}
+		sr.tail = append(sr.tail[:0], buf...)
+	}
+	return n, err
+}
@@ -0,0 +1,64 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStats(t *testing.T) {
+	feeds := []string{
+		"caf\xe9",
+		"añejo \xef\xbf\xbd",
+		"\xef\xbb\xbfhola",
+		"<meta charset=\"shift_jis\">\x83e\x83X\x83g\x82",
+	}
+
+	var s Stats
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, feed := range feeds {
+			wg.Add(1)
+			go func(feed string) {
+				defer wg.Done()
+				r, err := NewReaderOpts(iotest.OneByteReader(strings.NewReader(feed)), WithStats(&s))
+				if err != nil {
+					t.Errorf("error en NewReaderOpts: %v", err)
+					return
+				}
+				if _, err := ioutil.ReadAll(iotest.OneByteReader(r)); err != nil {
+					t.Errorf("error en ReadAll: %v", err)
+				}
+			}(feed)
+		}
+	}
+	wg.Wait()
+
+	snap := s.Snapshot()
+	if snap.Readers != 40 {
+		t.Errorf("readers -> got: %d - expected: %d", snap.Readers, 40)
+	}
+	expected := map[string]int64{"ISO 8859-1": 10, "utf-8": 20, "shift_jis": 10}
+	for name, n := range expected {
+		if snap.Encodings[name] != n {
+			t.Errorf("encodings -> got: %v - expected: %v", snap.Encodings, expected)
+			break
+		}
+	}
+	evidence := map[Evidence]int64{EvidenceFallback: 10, EvidenceUTF8: 10, EvidenceBOM: 10, EvidenceMeta: 10}
+	for ev, n := range evidence {
+		if snap.Evidence[ev] != n {
+			t.Errorf("evidence -> got: %v - expected: %v", snap.Evidence, evidence)
+			break
+		}
+	}
+	// café, añejo �, hola and <meta charset="shift_jis">テスト�
+	if bytes := int64(5+10+4+26+9+3) * 10; snap.Bytes != bytes {
+		t.Errorf("bytes -> got: %d - expected: %d", snap.Bytes, bytes)
+	}
+	if snap.Replacements != 20 {
+		t.Errorf("replacements -> got: %d - expected: %d", snap.Replacements, 20)
+	}
+}
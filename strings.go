@@ -0,0 +1,72 @@
+package txtopener
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// DecodeString returns s, text in the encoding labeled label, converted to UTF-8. If label is
+// empty the encoding is detected as NewReader does, and the BOM is removed.
+// It is meant for small strings, such as database fields or header values
+func DecodeString(s, label string) (string, error) {
+	if label == "" {
+		r, err := decodingReader(strings.NewReader(s), newOptions(nil))
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadAll(r)
+		return string(b), err
+	}
+	e, _, err := lookup(label)
+	if err != nil {
+		return "", err
+	}
+	return e.NewDecoder().String(s)
+}
+
+// EncodeString returns s converted to the encoding labeled label. It fails if s holds
+// characters the encoding doesn't have
+func EncodeString(s, label string) ([]byte, error) {
+	e, _, err := lookup(label)
+	if err != nil {
+		return nil, err
+	}
+	return e.NewEncoder().Bytes([]byte(s))
+}
+
+// Convert returns b converted from the encoding labeled fromLabel to the one labeled toLabel,
+// UTF-8 if empty. If fromLabel is empty the encoding of b is detected as NewReader does, and
+// its BOM removed. b is returned as it is when both encodings are the same
+func Convert(b []byte, fromLabel, toLabel string) ([]byte, error) {
+	if toLabel == "" {
+		toLabel = "utf-8"
+	}
+	to, toName, err := lookup(toLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	var from encoding.Encoding
+	var fromName string
+	if fromLabel == "" {
+		from, fromName, _ = determineEncoding(b, newOptions(nil))
+		for _, bom := range boms {
+			if bytes.HasPrefix(b, bom.bom) {
+				b = b[len(bom.bom):]
+				break
+			}
+		}
+	} else if from, fromName, err = lookup(fromLabel); err != nil {
+		return nil, err
+	}
+	if fromName == toName || from == encoding.Nop && toName == "utf-8" {
+		return b, nil
+	}
+
+	out, _, err := transform.Bytes(transform.Chain(from.NewDecoder(), to.NewEncoder()), b)
+	return out, err
+}
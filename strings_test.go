@@ -0,0 +1,72 @@
+package txtopener
+
+import "testing"
+
+func TestDecodeString(t *testing.T) {
+	var tests = []struct {
+		s        string
+		label    string
+		expected string
+	}{
+		{"caf\xe9", "windows-1252", "café"},
+		{"\x83e\x83X\x83g", "sjis", "テスト"},
+		{"\xf0\xd2\xc9\xd7\xc5\xd4", "koi8-r", "Привет"},
+		{"\xef\xbb\xbfaño", "", "año"},
+		{"hola", "", "hola"},
+	}
+
+	for i, tt := range tests {
+		got, err := DecodeString(tt.s, tt.label)
+		if err != nil {
+			t.Errorf("%d. error en DecodeString: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.s, got, tt.expected)
+		}
+		if tt.label == "" {
+			continue
+		}
+		back, err := EncodeString(got, tt.label)
+		if err != nil || string(back) != tt.s {
+			t.Errorf("%d. encoded: %q -> got: %q, %v - expected: %q", i, got, back, err, tt.s)
+		}
+	}
+
+	if _, err := DecodeString("x", "no-such-encoding"); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+	if _, err := EncodeString("日本", "latin1"); err == nil {
+		t.Errorf("expected an error for characters latin1 doesn't have")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var tests = []struct {
+		b        string
+		from, to string
+		expected string
+	}{
+		{"caf\xe9", "windows-1252", "", "café"},
+		{"café", "utf-8", "windows-1252", "caf\xe9"},
+		{"\xf0\xd2\xc9\xd7\xc5\xd4", "koi8-r", "windows-1251", "\xcf\xf0\xe8\xe2\xe5\xf2"},
+		{"\xff\xfea\x00\xf1\x00o\x00", "", "latin1", "a\xf1o"},
+		{"\xef\xbb\xbfaño", "", "utf-8", "año"},
+		{"sin cambios", "latin1", "iso-8859-1", "sin cambios"},
+	}
+
+	for i, tt := range tests {
+		got, err := Convert([]byte(tt.b), tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%d. error en Convert: %v", i, err)
+			continue
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.b, got, tt.expected)
+		}
+	}
+
+	if _, err := Convert([]byte("日本"), "utf-8", "latin1"); err == nil {
+		t.Errorf("expected an error for characters latin1 doesn't have")
+	}
+}
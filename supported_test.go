@@ -0,0 +1,83 @@
+package txtopener
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestSupportedEncodings(t *testing.T) {
+	for _, label := range whatwgLabels {
+		if _, _, err := lookup(label); err != nil {
+			t.Errorf("error en lookup: %v", err)
+		}
+	}
+
+	encs := SupportedEncodings()
+	if !sort.SliceIsSorted(encs, func(i, j int) bool { return encs[i].Name < encs[j].Name }) {
+		t.Errorf("encodings not sorted by name")
+	}
+	labels := make(map[string]string)
+	for _, enc := range encs {
+		for _, label := range enc.Labels {
+			labels[label] = enc.Name
+		}
+	}
+
+	var tests = []struct {
+		label string
+		name  string
+	}{
+		{"utf8", "utf-8"},
+		{"latin1", "windows-1252"},
+		{"sjis", "shift_jis"},
+		{"cp949", "euc-kr"},
+		{"cp437", "ibm437"},
+		{"armscii8", "armscii-8"},
+		{"utf-7", "utf-7"},
+		{"x-user-defined", "x-user-defined"},
+		{"iso-2022-kr", ""},
+	}
+
+	for i, tt := range tests {
+		if got := labels[tt.label]; got != tt.name {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.label, got, tt.name)
+		}
+	}
+}
+
+func TestCanonicalLabel(t *testing.T) {
+	var tests = []struct {
+		label string
+		name  string
+		ok    bool
+	}{
+		{"latin1", "windows-1252", true},
+		{"cp-1252", "windows-1252", true},
+		{"UTF8", "utf-8", true},
+		{" Shift_JIS ", "shift_jis", true},
+		{"Shift JIS", "shift_jis", true},
+		{"KOI8R", "koi8-r", true},
+		{"iso 8859-8-i", "iso-8859-8-i", true},
+		{"CP 437", "ibm437", true},
+		{"UTF-7", "utf-7", true},
+		{"iso-2022-kr", "", false},
+		{"no-such-encoding", "", false},
+	}
+
+	for i, tt := range tests {
+		if name, ok := CanonicalLabel(tt.label); name != tt.name || ok != tt.ok {
+			t.Errorf("%d. feeded: %q -> got: %q, %v - expected: %q, %v", i, tt.label, name, ok, tt.name, tt.ok)
+		}
+	}
+
+	// the loose forms of the labels of different encodings never clash
+	names := make(map[string]string)
+	for _, enc := range SupportedEncodings() {
+		for _, label := range enc.Labels {
+			if name, ok := names[looseLabel(label)]; ok && name != enc.Name {
+				t.Errorf("%s is loosely both %s and %s", label, name, enc.Name)
+			}
+			names[looseLabel(label)] = enc.Name
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package txtopener
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// ErrNotSingleByte is returned when a conversion table is requested for an encoding
+// that doesn't map every character to a single byte
+var ErrNotSingleByte = errors.New("txtopener: not a single-byte encoding")
+
+// TableFormat selects the output format of ExportTable
+type TableFormat int
+
+const (
+	// TableCSV writes a "byte,code_points" header followed by one record per byte
+	TableCSV TableFormat = iota
+	// TableJSON writes an array of {"byte": ..., "code_points": [...]} objects
+	TableJSON
+	// TableUnicode writes the mapping-table format of the files on unicode.org,
+	// which LoadCharmap reads back
+	TableUnicode
+)
+
+// Mapping is one entry of a single-byte conversion table.
+// Runes is empty when the byte is undefined in the encoding
+type Mapping struct {
+	Byte  byte
+	Runes []rune
+}
+
+// byteDecoder is implemented by the single-byte encodings of x/text and by Charmap
+type byteDecoder interface {
+	DecodeByte(b byte) rune
+}
+
+// Table returns the 256 byte to rune mappings of the single-byte encoding e
+func Table(e encoding.Encoding) ([]Mapping, error) {
+	table := make([]Mapping, 256)
+	for b := 0; b < 256; b++ {
+		table[b].Byte = byte(b)
+		switch enc := e.(type) {
+		case *Charmap:
+			table[b].Runes = []rune(enc.decode[b])
+		case tscii:
+			table[b].Runes = []rune(enc.decode[b])
+		case byteDecoder:
+			if r := enc.DecodeByte(byte(b)); r != utf8.RuneError {
+				table[b].Runes = []rune{r}
+			}
+		default:
+			return nil, ErrNotSingleByte
+		}
+	}
+	return table, nil
+}
+
+// ExportTable writes the byte to rune mapping of the single-byte encoding e to w in the given format
+func ExportTable(w io.Writer, e encoding.Encoding, format TableFormat) error {
+	table, err := Table(e)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case TableCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"byte", "code_points"}); err != nil {
+			return err
+		}
+		for _, m := range table {
+			if err := cw.Write([]string{hexByte(m.Byte), strings.Join(hexRunes(m.Runes), "+")}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case TableJSON:
+		type entry struct {
+			Byte       string   `json:"byte"`
+			CodePoints []string `json:"code_points,omitempty"`
+		}
+		entries := make([]entry, len(table))
+		for i, m := range table {
+			entries[i] = entry{hexByte(m.Byte), hexRunes(m.Runes)}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+
+	case TableUnicode:
+		for _, m := range table {
+			var err error
+			if len(m.Runes) == 0 {
+				_, err = fmt.Fprintf(w, "%s\t\t#UNDEFINED\n", hexByte(m.Byte))
+			} else {
+				_, err = fmt.Fprintf(w, "%s\t%s\n", hexByte(m.Byte), strings.Join(hexRunes(m.Runes), "+"))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("txtopener: unknown table format %d", format)
+}
+
+func hexByte(b byte) string {
+	return fmt.Sprintf("0x%02X", b)
+}
+
+func hexRunes(rs []rune) []string {
+	var s []string
+	for _, r := range rs {
+		s = append(s, fmt.Sprintf("0x%04X", r))
+	}
+	return s
+}
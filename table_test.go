@@ -0,0 +1,46 @@
+package txtopener
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestExportTable(t *testing.T) {
+	table, err := Table(charmap.Windows1252)
+	if err != nil {
+		t.Fatalf("error en Table: %v", err)
+	}
+	if len(table) != 256 || len(table[0x80].Runes) != 1 || table[0x80].Runes[0] != '€' || len(table[0x81].Runes) != 0 {
+		t.Errorf("unexpected windows-1252 table: %v %v", table[0x80], table[0x81])
+	}
+
+	var buf bytes.Buffer
+	if err := ExportTable(&buf, charmap.Windows1252, TableUnicode); err != nil {
+		t.Fatalf("error en ExportTable: %v", err)
+	}
+	cm, err := LoadCharmap(&buf, "reloaded")
+	if err != nil {
+		t.Fatalf("error en LoadCharmap: %v", err)
+	}
+	for b := 0; b < 256; b++ {
+		if got, expected := cm.DecodeByte(byte(b)), charmap.Windows1252.DecodeByte(byte(b)); got != expected {
+			t.Errorf("%#x. got: %U - expected: %U", b, got, expected)
+		}
+	}
+
+	buf.Reset()
+	if err := ExportTable(&buf, charmap.Windows1252, TableCSV); err != nil {
+		t.Fatalf("error en ExportTable: %v", err)
+	}
+	if !strings.Contains(buf.String(), "0x80,0x20AC\n") {
+		t.Errorf("csv output lacks the euro sign mapping")
+	}
+
+	if _, err := Table(japanese.ShiftJIS); err != ErrNotSingleByte {
+		t.Errorf("expected ErrNotSingleByte for shift_jis, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package txtopener
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// NewTerminalReader returns an io.Reader that converts interactive input read from r, usually
+// os.Stdin, from the encoding of the terminal to UTF-8: the console input code page on Windows
+// and the charset of the locale (LC_ALL, LC_CTYPE, LANG) elsewhere.
+// Unlike NewReader nothing is read ahead: every line typed is converted and returned as soon as r
+// returns it, so prompts work as expected
+func NewTerminalReader(r io.Reader) io.Reader {
+	e := terminalEncoding()
+	if e == nil || e == encoding.Nop {
+		return r
+	}
+	return transform.NewReader(r, e.NewDecoder())
+}
+
+// localeEncoding returns the encoding of the charset named by the locale in the environment,
+// nil for UTF-8 or when there is none, as in the C locale
+func localeEncoding(getenv func(string) string) encoding.Encoding {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale = getenv(name); locale != "" {
+			break
+		}
+	}
+	e, name := lookupLabel(localeCharset(locale))
+	if name == "utf-8" {
+		return nil
+	}
+	return e
+}
+
+// localeCharsets maps the charset names of locales that aren't encoding labels to theirs
+var localeCharsets = map[string]string{
+	"eucjp":     "euc-jp",
+	"euckr":     "euc-kr",
+	"euccn":     "gb2312",
+	"koi8r":     "koi8-r",
+	"koi8u":     "koi8-u",
+	"big5hkscs": "big5-hkscs",
+}
+
+// localeCharset returns the encoding label of the charset of locale, as in ru_RU.KOI8-R@euro
+func localeCharset(locale string) string {
+	i := strings.IndexByte(locale, '.')
+	if i < 0 {
+		return ""
+	}
+	charset := strings.ToLower(locale[i+1:])
+	if j := strings.IndexByte(charset, '@'); j >= 0 {
+		charset = charset[:j]
+	}
+	if label, ok := localeCharsets[charset]; ok {
+		return label
+	}
+	if strings.HasPrefix(charset, "iso8859") && !strings.HasPrefix(charset, "iso8859-") {
+		return "iso-8859-" + charset[len("iso8859"):]
+	}
+	return charset
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package txtopener
+
+import (
+	"os"
+
+	"golang.org/x/text/encoding"
+)
+
+// terminalEncoding returns the encoding of the charset of the locale, nil for UTF-8
+func terminalEncoding() encoding.Encoding {
+	return localeEncoding(os.Getenv)
+}
@@ -0,0 +1,48 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLocaleEncoding(t *testing.T) {
+	var tests = []struct {
+		env      map[string]string
+		expected string
+	}{
+		{map[string]string{"LANG": "ru_RU.KOI8-R"}, "KOI8-R"},
+		{map[string]string{"LANG": "de_DE.ISO8859-15@euro"}, "ISO 8859-15"},
+		{map[string]string{"LANG": "ja_JP.eucJP"}, "EUC-JP"},
+		{map[string]string{"LC_CTYPE": "pl_PL.iso88592", "LANG": "en_US.UTF-8"}, "ISO 8859-2"},
+		{map[string]string{"LC_ALL": "es_AR.UTF-8", "LANG": "ru_RU.KOI8-R"}, ""},
+		{map[string]string{"LANG": "C"}, ""},
+		{map[string]string{}, ""},
+	}
+
+	for i, tt := range tests {
+		e := localeEncoding(func(name string) string { return tt.env[name] })
+		got := ""
+		if e != nil {
+			got = e.(interface{ String() string }).String()
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %v -> got: %q - expected: %q", i, tt.env, got, tt.expected)
+		}
+	}
+}
+
+func TestNewTerminalReader(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the console code page isn't taken from the environment")
+	}
+	t.Setenv("LC_ALL", "ru_RU.KOI8-R")
+	b, err := ioutil.ReadAll(NewTerminalReader(strings.NewReader("\xf0\xd2\xc9\xd7\xc5\xd4\n")))
+	if err != nil {
+		t.Fatalf("error en NewTerminalReader: %v", err)
+	}
+	if string(b) != "Привет\n" {
+		t.Errorf("got: %q - expected: %q", b, "Привет\n")
+	}
+}
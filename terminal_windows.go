@@ -0,0 +1,19 @@
+package txtopener
+
+import (
+	"syscall"
+
+	"golang.org/x/text/encoding"
+)
+
+var procGetConsoleCP = syscall.NewLazyDLL("kernel32.dll").NewProc("GetConsoleCP")
+
+// terminalEncoding returns the encoding of the console input code page, nil for UTF-8
+func terminalEncoding() encoding.Encoding {
+	cp, _, _ := procGetConsoleCP.Call()
+	e, name := lookupLabel(codePageLabel(uint32(cp)))
+	if name == "utf-8" {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,170 @@
+package txtopener
+
+import (
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// tsciiCharmap is the byte to text table of TSCII 1.7. Many bytes stand for a consonant
+// together with its vowel sign or virama
+var tsciiCharmap = func() *Charmap {
+	table := highTable("tscii",
+		"௦௧\ufffdஜஷஸஹ\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd௨௩௪"+
+			"௫‘’“”௬௭௮௯\ufffd\ufffd\ufffd\ufffd௰௱௲"+
+			"\ufffdாிீுூெேை©ௗஅஆஇஈஉ"+
+			"ஊஎஏஐஒஓஔஃகஙசஞடணதந"+
+			"பமயரலவழளறன\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd"+
+			"\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd"+
+			"\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd"+
+			"\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffd\ufffdஇ\ufffd")
+	for b, s := range map[byte]string{
+		0x82: "ஸ்ரீ", 0x87: "க்ஷ", 0x88: "ஜ்", 0x89: "ஷ்", 0x8a: "ஸ்", 0x8b: "ஹ்", 0x8c: "க்ஷ்",
+		0x99: "ஙு", 0x9a: "ஞு", 0x9b: "ஙூ", 0x9c: "ஞூ", 0xca: "டி", 0xcb: "டீ",
+	} {
+		table[b] = s
+	}
+	// the consonants with u, uu and the virama follow the same order
+	for i, c := range []rune("கசடணதநபமயரலவழளறன") {
+		table[byte(0xcc+i)] = string(c) + "ு"
+		table[byte(0xdc+i)] = string(c) + "ூ"
+	}
+	for i, c := range []rune("கஙசஞடணதநபமயரலவழளறன") {
+		table[byte(0xec+i)] = string(c) + "்"
+	}
+	return NewCharmap("tscii", table)
+}()
+
+// tscii is the Tamil TSCII encoding. TSCII stores text in visual order: the vowel signs drawn
+// to the left of a consonant (ெ ே ை, and the first half of ொ ோ ௌ) come before it, while
+// Unicode puts every vowel sign after its consonant
+type tscii struct {
+	*Charmap
+}
+
+// NewDecoder implements encoding.Encoding
+func (t tscii) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: transform.Chain(charmapDecoder{c: t.Charmap}, tamilLogical{})}
+}
+
+// NewEncoder implements encoding.Encoding
+func (t tscii) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: transform.Chain(tamilVisual{}, charmapEncoder{c: t.Charmap})}
+}
+
+// maxTamilCluster is the longest run of UTF-8 reordered at once: a prefix vowel sign,
+// the conjunct க்ஷ and the second half of a two-part vowel sign
+const maxTamilCluster = 5 * 3
+
+// tamilLogical moves the vowel signs found before a consonant in visual order after it,
+// joining the two parts of ொ ோ ௌ
+type tamilLogical struct {
+	transform.NopResetter
+}
+
+func (tamilLogical) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		s := src[nSrc:]
+		if !atEOF && len(s) < maxTamilCluster && !utf8.FullRune(s) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		v, size := utf8.DecodeRune(s)
+		n, out := size, s[:size]
+		if isTamilPrefix(v) {
+			if !atEOF && len(s) < maxTamilCluster {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			if end := tamilCluster(s[size:]); end > 0 {
+				out = append([]byte(nil), s[size:size+end]...)
+				n = size + end
+				second, m := utf8.DecodeRune(s[n:])
+				switch {
+				case v == 'ெ' && second == 'ா':
+					v, n = 'ொ', n+m
+				case v == 'ே' && second == 'ா':
+					v, n = 'ோ', n+m
+				case (v == 'ெ' || v == 'ே') && second == 'ௗ':
+					// some encoders write ௌ with ே
+					v, n = 'ௌ', n+m
+				}
+				out = utf8.AppendRune(out, v)
+			}
+		}
+		if nDst+len(out) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// tamilVisual is the reverse of tamilLogical: it puts the vowel signs drawn to the left of
+// a consonant before it, splitting ொ ோ ௌ in their two parts
+type tamilVisual struct {
+	transform.NopResetter
+}
+
+func (tamilVisual) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		s := src[nSrc:]
+		if !atEOF && len(s) < maxTamilCluster && !utf8.FullRune(s) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		_, size := utf8.DecodeRune(s)
+		n, out := size, s[:size]
+		if end := tamilCluster(s); end > 0 {
+			if !atEOF && len(s) < maxTamilCluster {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			v, m := utf8.DecodeRune(s[end:])
+			var first, second rune
+			switch v {
+			case 'ெ', 'ே', 'ை':
+				first = v
+			case 'ொ':
+				first, second = 'ெ', 'ா'
+			case 'ோ':
+				first, second = 'ே', 'ா'
+			case 'ௌ':
+				first, second = 'ெ', 'ௗ'
+			}
+			if first != 0 {
+				out = utf8.AppendRune(nil, first)
+				out = append(out, s[:end]...)
+				if second != 0 {
+					out = utf8.AppendRune(out, second)
+				}
+				n = end + m
+			}
+		}
+		if nDst+len(out) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += copy(dst[nDst:], out)
+		nSrc += n
+	}
+	return nDst, nSrc, nil
+}
+
+// tamilCluster returns the length of the consonant at the start of s, 0 if there is none.
+// க்ஷ, a single letter in TSCII, counts as one consonant
+func tamilCluster(s []byte) int {
+	r, n := utf8.DecodeRune(s)
+	if r < 'க' || r > 'ஹ' {
+		return 0
+	}
+	if r == 'க' {
+		if virama, m := utf8.DecodeRune(s[n:]); virama == '்' {
+			if ssa, k := utf8.DecodeRune(s[n+m:]); ssa == 'ஷ' {
+				return n + m + k
+			}
+		}
+	}
+	return n
+}
+
+func isTamilPrefix(r rune) bool {
+	return r == 'ெ' || r == 'ே' || r == 'ை'
+}
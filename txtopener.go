@@ -1,5 +1,5 @@
 // Package txtopener provides helper functions to read files encoded as UTF-8, BOMBed UTF-8, BOMBed UTF-16-LE,
-// BOMBed UTF-16-BE or any other known encoding as if they were UTF-8 files.
+// BOMBed UTF-16-BE, BOMBed UTF-32-LE, BOMBed UTF-32-BE or any other known encoding as if they were UTF-8 files.
 // For all BOMbed files the BOM is stripped out.
 // All files without a BOM are treated with the reader provided by charset.NewReader() in order to get translated
 // from the original character encoding to UTF-8
@@ -8,19 +8,25 @@
 
 import (
 	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"io"
+	"math"
 	"mime"
 	"os"
 	"strings"
 	"unicode/utf8"
 
 	"golang.org/x/net/html"
-	"golang.org/x/net/html/charset"
 	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
 	"golang.org/x/text/transform"
 )
 
+// ErrUnknownEncoding is returned when an encoding label can not be resolved
+var ErrUnknownEncoding = errors.New("txtopener: unknown encoding")
+
 // MustOpenAndClose calls os.Open and returns a reader that converts the content to UTF-8 without BOM
 // and a function to close the file who panics if there is an error
 func MustOpenAndClose(name string) (io.Reader, func()) {
@@ -35,122 +41,510 @@ func MustOpenAndClose(name string) (io.Reader, func()) {
 	}
 }
 
+// OpenAndClose is like MustOpenAndClose but it returns the errors opening, reading the preview of
+// and closing the file instead of panicking
+func OpenAndClose(name string) (io.Reader, func() error, error) {
+	rc, err := Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rc, rc.Close, nil
+}
+
+// Open opens the named file and returns a reader that converts its content to UTF-8 without BOM.
+// Closing the reader closes the file
+func Open(name string) (io.ReadCloser, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decodingReader(file, newOptions([]Option{WithFilename(name)}))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return closing(r, file), nil
+}
+
+// NewReadCloser is like NewReader but closing the reader returned closes rc, so that HTTP
+// bodies and files can be wrapped once. Instead of panicking, the errors reading the preview
+// of rc are returned by Read
+func NewReadCloser(rc io.ReadCloser) io.ReadCloser {
+	r, err := NewReaderE(rc)
+	if err != nil {
+		r = &errReader{err}
+	}
+	return closing(r, rc)
+}
+
+// closing returns a reader of r whose Close calls c. It can seek if r can
+func closing(r io.Reader, c io.Closer) io.ReadCloser {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		return readSeekCloser{rs, c}
+	}
+	return readCloser{r, c}
+}
+
+// readCloser is a reader whose Close closes the source it reads from
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readSeekCloser is a readCloser which can seek
+type readSeekCloser struct {
+	io.ReadSeeker
+	io.Closer
+}
+
 // NewReader returns an io.Reader that converts the content of r to UTF-8 without BOM.
 // It calls charset.DetermineEncoding() to find out what r's enconding is
 func NewReader(r io.Reader) io.Reader {
-	nr, err := newReader(r, "")
+	nr, err := decodingReader(r, newOptions(nil))
+	if err != nil {
+		panic(err)
+	}
+	return nr
+}
+
+// NewReaderContentType is like NewReader but the charset of contentType, the Content-Type of r
+// such as the header of an HTTP response, takes part in the detection as in charset.NewReader:
+// only a BOM wins over it. Like NewReader it panics if reading the preview of r fails
+func NewReaderContentType(r io.Reader, contentType string) io.Reader {
+	o := newOptions(nil)
+	o.contentType = contentType
+	nr, err := decodingReader(r, o)
+	if err != nil {
+		panic(err)
+	}
+	return nr
+}
+
+// NewReaderWithEncoding returns an io.Reader that converts the content of r from enc to UTF-8
+// without BOM, for when the encoding is already known. Nothing is detected, so no preview is read
+func NewReaderWithEncoding(r io.Reader, enc encoding.Encoding) io.Reader {
+	nr, err := stripBOM(decoder(r, enc, "", newOptions(nil)))
+	if err != nil {
+		return &errReader{err}
+	}
+	return nr
+}
+
+// NewReaderLabel is like NewReaderWithEncoding for the encoding labeled label, such as "latin1",
+// "sjis" or "cp1251". Labels are resolved following the WHATWG rules, as charset.Lookup does,
+// and the legacy encodings of the package are known as well
+func NewReaderLabel(label string, r io.Reader) (io.Reader, error) {
+	e, _, err := lookup(label)
+	if err != nil {
+		return nil, err
+	}
+	return NewReaderWithEncoding(r, e), nil
+}
+
+// NewReaderE is like NewReader but it returns the errors found while reading the preview of r
+// instead of panicking
+func NewReaderE(r io.Reader) (io.Reader, error) {
+	return decodingReader(r, newOptions(nil))
+}
+
+// NewReaderResult is like NewReader but it also returns what was found out about the encoding
+// of r, so it can be logged
+func NewReaderResult(r io.Reader) (io.Reader, Result) {
+	nr, res, err := decodingResult(r, newOptions(nil))
+	if err != nil {
+		panic(err)
+	}
+	return nr, res
+}
+
+// decodingReader returns an io.Reader that converts the content of r to UTF-8 without BOM
+func decodingReader(r io.Reader, o *options) (io.Reader, error) {
+	nr, _, err := decodingResult(r, o)
+	return nr, err
+}
+
+// decodingResult is like decodingReader but it also returns the result of the detection
+func decodingResult(r io.Reader, o *options) (io.Reader, Result, error) {
+	if rs, ok := r.(io.ReadSeeker); ok && o.seekable == 0 && o.scratch == nil {
+		return seekingResult(rs, o)
+	}
+	return streamingResult(r, o)
+}
+
+// streamingResult is decodingResult for readers which can't be rewound
+func streamingResult(r io.Reader, o *options) (io.Reader, Result, error) {
+	nr, res, err := newReader(r, o)
 	if err != nil {
 		if err == io.EOF {
-			return r
+			_, res, _ = newResult(nil, o)
+			if o.bomPolicy == BOMAdd {
+				return bytes.NewReader([]byte(utf8BOM)), res, nil
+			}
+			if o.seekable > 0 {
+				return bytes.NewReader(nil), res, nil
+			}
+			return r, res, nil
+		}
+		return nil, Result{}, err
+	}
+
+	if o.bomPolicy != BOMPreserve {
+		if nr, err = stripBOM(nr); err != nil {
+			return nil, Result{}, err
 		}
-		panic(err)
 	}
+	if o.bomPolicy == BOMAdd {
+		nr = io.MultiReader(strings.NewReader(utf8BOM), nr)
+	}
+	nr = o.stats.reader(nr, res)
+	if o.seekable > 0 {
+		nr, err = bufferSmall(nr, o.seekable)
+	}
+	return nr, res, err
+}
 
-	// discarding the utf-8 BOM mark (EF BB BF)
+// stripBOM discards the utf-8 BOM mark (EF BB BF) at the start of the decoded output nr
+func stripBOM(nr io.Reader) (io.Reader, error) {
 	bom := make([]byte, 3)
 	if n, err := io.ReadFull(nr, bom); err != nil {
 		if err != io.EOF && err != io.ErrUnexpectedEOF {
-			panic(err)
+			return nil, err
 		}
 		if n < len(bom) {
-			return bytes.NewReader(bom[:n])
+			return bytes.NewReader(bom[:n]), nil
 		}
 	}
 
 	if bom[0] != 0xef || bom[1] != 0xbb || bom[2] != 0xbf {
 		nr = io.MultiReader(bytes.NewReader(bom), nr)
 	}
-	return nr
+	return nr, nil
 }
 
-// newReader returns an io.Reader that converts the content of r to UTF-8.
+// newReader returns an io.Reader that converts the content of r to UTF-8 and the result of the detection.
 // It calls DetermineEncoding to find out what r's encoding is.
-func newReader(r io.Reader, contentType string) (io.Reader, error) {
-	preview := make([]byte, 10240)
+func newReader(r io.Reader, o *options) (io.Reader, Result, error) {
+	start := position(r)
+	preview := o.scratch.previewBuffer(previewSize(r, o))
 	n, err := io.ReadFull(r, preview)
+	o.scratch.keepPreview(preview[:n])
 	switch {
 	case err == io.ErrUnexpectedEOF:
 		preview = preview[:n]
 	case err != nil:
-		return nil, err
+		return nil, Result{}, err
+	}
+	if !rewind(r, start) {
+		r = io.MultiReader(bytes.NewReader(preview), r)
 	}
-	r = io.MultiReader(bytes.NewReader(preview), r)
 
-	if e, _, _ := determineEncoding(preview, contentType); e != encoding.Nop {
-		r = transform.NewReader(r, e.NewDecoder())
+	if o.base64 {
+		if enc := base64Encoding(preview, n < len(preview)); enc != nil {
+			inner := *o
+			inner.base64 = false
+			// the preview is still read from, it can't be reused for the inner one
+			inner.scratch = nil
+			return newReader(base64.NewDecoder(enc, &base64Text{r: r}), &inner)
+		}
 	}
-	return r, nil
+
+	if o.deterministic {
+		if err := checkDeterministic(o.allDetectors(), preview); err != nil {
+			return nil, Result{}, err
+		}
+	}
+	e, res, src := newResult(preview, o)
+	if err := checkAllowed(res.Encoding, o); err != nil {
+		return nil, Result{}, err
+	}
+	if err := checkGuessed(preview, res.Encoding, src, o); err != nil {
+		return nil, Result{}, err
+	}
+	if err := checkBinary(preview, res.Encoding, o); err != nil {
+		return nil, Result{}, err
+	}
+	if revalidates(res, o) {
+		return transform.NewReader(r, newRevalidator(e, res.Encoding, o)), res, nil
+	}
+	return decoder(r, e, res.Encoding, o), res, nil
+}
+
+// decoder returns an io.Reader that converts the content of r from e, named name, to UTF-8
+func decoder(r io.Reader, e encoding.Encoding, name string, o *options) io.Reader {
+	if o.scratch != nil {
+		return o.scratch.decoder(r, e, name, o)
+	}
+	t := transformer(e, name, o)
+	if t == nil {
+		return r
+	}
+	return transform.NewReader(r, t)
+}
+
+// transformer returns the transformer converting from e, named name, to UTF-8, nil if nothing
+// has to be done
+func transformer(e encoding.Encoding, name string, o *options) transform.Transformer {
+	var ts []transform.Transformer
+	if e != encoding.Nop {
+		ts = append(ts, e.NewDecoder())
+	}
+	if o.visualToLogical && name == "iso-8859-8" {
+		ts = append(ts, visualToLogical{})
+	}
+	if o.strict {
+		ts = append(ts, strictUTF8{})
+	}
+	if o.mojibake {
+		ts = append(ts, RepairMojibake)
+	}
+	if o.innerBOMs {
+		ts = append(ts, &innerBOMStripper{})
+	}
+	switch len(ts) {
+	case 0:
+		return nil
+	case 1:
+		return ts[0]
+	}
+	return transform.Chain(ts...)
+}
+
+// DetectEncoding returns the encoding of content, the start of a file or stream, as NewReader
+// would determine it given the Content-Type contentType, which may be empty, along with its name
+// and whether it was certain: told by a BOM or by contentType rather than found in content, see Evidence.Guessed.
+// Only the first 10240 bytes of content, or as many as WithPreviewSize or SetDefaultPreviewSize tell, are examined. The first of these that applies decides:
+//
+//  1. a BOM
+//  2. the charset of contentType
+//  3. an XML declaration, a CSS @charset rule or a meta element declaring the charset
+//  4. UTF-8 is taken when content is valid UTF-8 beyond ASCII
+//  5. the claim of the detectors with the highest confidence, the first added on a tie: those
+//     given WithDetector come before the registered ones
+//  6. the multi-byte charset whose Chinese, Japanese or Korean model fits best or else the
+//     single-byte charset whose language model does, the first of the models on a tie, unless
+//     WithoutModels is given
+//  7. the encoding hinted by the extension of the file given WithFilename, unless content is ASCII
+//  8. the fallback encoding, ISO-8859-1 unless changed
+//
+// opts customize the detection as they do for NewReaderOpts, so that files can be labeled as the
+// readers would decode them without decoding them. A non empty contentType overrides WithContentType
+func DetectEncoding(content []byte, contentType string, opts ...Option) (e encoding.Encoding, name string, certain bool) {
+	o := newOptions(opts)
+	if contentType != "" {
+		o.contentType = contentType
+	}
+	return determineEncoding(content, o)
 }
 
 // determineEncoding determines the encoding of an HTML document by examining
-// up to the first 10240 bytes of content and the declared Content-Type.
+// up to the first o.preview() bytes of content and the declared Content-Type.
 //
 // See http://www.whatwg.org/specs/web-apps/current-work/multipage/parsing.html#determining-the-character-encoding
-func determineEncoding(content []byte, contentType string) (e encoding.Encoding, name string, certain bool) {
-	if len(content) > 10240 {
-		content = content[:10240]
+func determineEncoding(content []byte, o *options) (e encoding.Encoding, name string, certain bool) {
+	e, name, src, _ := detectEncoding(content, o)
+	return e, name, src <= EvidenceContentType
+}
+
+// Evidence tells which step of the detection decided the encoding, as the Result and the
+// Report of a reader give it
+type Evidence int
+
+const (
+	// EvidenceBOM is a byte order mark, the signature of UTF-7 included
+	EvidenceBOM Evidence = iota + 1
+	// EvidenceContentType is the charset of the Content-Type
+	EvidenceContentType
+	// EvidenceXMLDeclaration is the encoding of the XML declaration content starts with
+	EvidenceXMLDeclaration
+	// EvidenceCSSCharset is the @charset rule CSS content starts with
+	EvidenceCSSCharset
+	// EvidenceMeta is a meta element, or in fragment mode the charset attribute of any element
+	EvidenceMeta
+	// EvidenceUTF8 is content valid as UTF-8 beyond ASCII
+	EvidenceUTF8
+	// EvidenceDetector is the claim of a detector given WithDetector or registered
+	EvidenceDetector
+	// EvidenceModel is the language models of the multi-byte and the single-byte charsets
+	EvidenceModel
+	// EvidenceExtension is the encoding hinted by the extension of the file name
+	EvidenceExtension
+	// EvidenceFallback is the fallback encoding, taken when nothing else applies
+	EvidenceFallback
+)
+
+var evidenceNames = [...]string{
+	EvidenceBOM:            "bom",
+	EvidenceContentType:    "content-type",
+	EvidenceXMLDeclaration: "xml-declaration",
+	EvidenceCSSCharset:     "css-charset",
+	EvidenceMeta:           "meta",
+	EvidenceUTF8:           "utf-8",
+	EvidenceDetector:       "detector",
+	EvidenceModel:          "model",
+	EvidenceExtension:      "extension",
+	EvidenceFallback:       "fallback",
+}
+
+func (ev Evidence) String() string {
+	if ev <= 0 || int(ev) >= len(evidenceNames) {
+		return fmt.Sprintf("Evidence(%d)", int(ev))
+	}
+	return evidenceNames[ev]
+}
+
+// Guessed tells whether the encoding was guessed, by the detectors, the language models, the
+// extension of the file name or the fallback, rather than told by the content or its Content-Type
+func (ev Evidence) Guessed() bool {
+	return ev >= EvidenceDetector
+}
+
+// confidences of the steps of the detection that don't rate what they find
+const (
+	// declarationConfidence is that of a charset the content declares, which is sometimes wrong
+	declarationConfidence = 0.9
+	// utf8Miss is how likely a character beyond ASCII of content in another encoding is valid UTF-8
+	utf8Miss = 0.1
+)
+
+// detectEncoding is like determineEncoding but it tells which step decided instead of whether it
+// was certain, and how confident, from 0 to 1, it is of it
+func detectEncoding(content []byte, o *options) (e encoding.Encoding, name string, src Evidence, confidence float64) {
+	if len(content) > o.preview() {
+		content = content[:o.preview()]
 	}
 
 	for _, b := range boms {
 		if bytes.HasPrefix(content, b.bom) {
-			e, name = charset.Lookup(b.enc)
-			return e, name, true
+			e, name = lookupLabel(b.enc)
+			return e, name, EvidenceBOM, 1
 		}
 	}
+	if hasUTF7Signature(content) {
+		return utf7{}, "utf-7", EvidenceBOM, 1
+	}
 
-	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+	if _, params, err := mime.ParseMediaType(o.contentType); err == nil {
 		if cs, ok := params["charset"]; ok {
-			if e, name = charset.Lookup(cs); e != nil {
-				return e, name, true
+			if e, name = lookupLabel(cs); e != nil && o.honored(name) {
+				return e, name, EvidenceContentType, 1
 			}
 		}
 	}
 
 	if len(content) > 0 {
-		e, name = prescan(content)
-		if e != nil {
-			return e, name, false
+		e, name, src = prescan(content, o.fragment)
+		if e != nil && o.honored(name) {
+			return e, name, src, declarationConfidence
 		}
 	}
 
+	// plain ASCII reads the same in any of the fallbacks
+	ascii := asciiPrefix(content) == len(content)
+
 	// Try to detect UTF-8.
 	// First eliminate any partial rune at the end.
-	for i := len(content) - 1; i >= 0 && i > len(content)-4; i-- {
-		b := content[i]
-		if b < 0x80 {
-			break
+	content = trimPartialRune(content)
+	wide := 0
+	for _, c := range content {
+		if c >= 0xc0 {
+			wide++
 		}
-		if utf8.RuneStart(b) {
-			content = content[:i]
-			break
+	}
+	if wide > 0 && utf8.Valid(content) {
+		return encoding.Nop, "utf-8", EvidenceUTF8, 1 - math.Pow(utf8Miss, float64(wide))
+	}
+
+	if e, name, confidence = detect(o.allDetectors(), content); e != nil {
+		return e, name, EvidenceDetector, math.Max(0, math.Min(confidence, 1))
+	}
+	if !o.noModels {
+		if e, name, confidence = detectMBCS(content, o.language); e != nil {
+			return e, name, EvidenceModel, confidence
+		}
+		if e, name, confidence = detectSBCS(content, o.language); e != nil {
+			return e, name, EvidenceModel, confidence
 		}
 	}
-	hasHighBit := false
-	for _, c := range content {
-		if c >= 0x80 {
-			hasHighBit = true
+
+	if ascii {
+		confidence = 1
+	} else if e, name = o.extensionHint(); e != nil {
+		return e, name, EvidenceExtension, extensionConfidence
+	}
+	if o.fallback != nil {
+		return o.fallback, o.fallbackName, EvidenceFallback, confidence
+	}
+	f := defaultFallback.Load()
+	return f.e, f.name, EvidenceFallback, confidence
+}
+
+// trimPartialRune returns b without the UTF-8 sequence cut at its end, if any
+func trimPartialRune(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i > len(b)-4; i-- {
+		c := b[i]
+		if c < 0x80 {
+			break
+		}
+		if utf8.RuneStart(c) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
 			break
 		}
 	}
-	if hasHighBit && utf8.Valid(content) {
-		return encoding.Nop, "utf-8", false
+	return b
+}
+
+// lookup resolves an encoding label following the WHATWG rules and returns the
+// encoding together with its canonical name. The legacy encodings of legacyLabels and those
+// registered with RegisterEncoding are known as well.
+// Unlike charset.Lookup the returned encoding is not wrapped with HTML escaping on encode
+func lookup(label string) (encoding.Encoding, string, error) {
+	if c, ok := lookupLegacy(label); ok {
+		return c, c.String(), nil
+	}
+	e, err := htmlindex.Get(label)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownEncoding, label)
 	}
+	name, _ := htmlindex.Name(e)
+	return e, name, nil
+}
 
-	// TODO: change default depending on user's locale?
-	// return charmap.Windows1252, "windows-1252", false
-	return charmap.ISO8859_1, "ISO 8859-1", false
+// lookupLabel is like lookup but reports unknown labels with a nil encoding, as charset.Lookup does
+func lookupLabel(label string) (encoding.Encoding, string) {
+	e, name, err := lookup(label)
+	if err != nil {
+		return nil, ""
+	}
+	return e, name
 }
 
-func prescan(content []byte) (e encoding.Encoding, name string) {
+// prescan looks for the charset declared by the XML declaration or the CSS @charset rule content
+// starts with, if any, or by its meta elements, and tells which declared it.
+// In fragment mode, meant for HTML snippets lacking a <head>, a meta content attribute is honored
+// without its http-equiv pragma and the charset attribute of any other element is a hint as well
+func prescan(content []byte, fragment bool) (e encoding.Encoding, name string, src Evidence) {
+	if e, name = xmlDeclaration(content); e != nil {
+		return e, name, EvidenceXMLDeclaration
+	}
+	if e, name = cssCharset(content); e != nil {
+		return e, name, EvidenceCSSCharset
+	}
 	z := html.NewTokenizer(bytes.NewReader(content))
 	for {
 		switch z.Next() {
 		case html.ErrorToken:
-			return nil, ""
+			return nil, "", 0
 
 		case html.StartTagToken, html.SelfClosingTagToken:
 			tagName, hasAttr := z.TagName()
 			if !bytes.Equal(tagName, []byte("meta")) {
+				if fragment && hasAttr {
+					if e, name = charsetAttr(z); e != nil {
+						return e, name, EvidenceMeta
+					}
+				}
 				continue
 			}
 			attrList := make(map[string]bool)
@@ -189,7 +583,7 @@ func prescan(content []byte) (e encoding.Encoding, name string) {
 					if e == nil {
 						name = fromMetaElement(string(val))
 						if name != "" {
-							e, name = charset.Lookup(name)
+							e, name = lookupLabel(name)
 							if e != nil {
 								needPragma = doNeedPragma
 							}
@@ -197,12 +591,12 @@ func prescan(content []byte) (e encoding.Encoding, name string) {
 					}
 
 				case "charset":
-					e, name = charset.Lookup(string(val))
+					e, name = lookupLabel(string(val))
 					needPragma = doNotNeedPragma
 				}
 			}
 
-			if needPragma == dontKnow || needPragma == doNeedPragma && !gotPragma {
+			if needPragma == dontKnow || needPragma == doNeedPragma && !gotPragma && !fragment {
 				continue
 			}
 
@@ -212,12 +606,29 @@ func prescan(content []byte) (e encoding.Encoding, name string) {
 			}
 
 			if e != nil {
-				return e, name
+				return e, name, EvidenceMeta
 			}
 		}
 	}
 }
 
+// charsetAttr resolves the charset attribute of the current tag of z, if any
+func charsetAttr(z *html.Tokenizer) (encoding.Encoding, string) {
+	for hasAttr := true; hasAttr; {
+		var key, val []byte
+		key, val, hasAttr = z.TagAttr()
+		if string(key) != "charset" {
+			continue
+		}
+		e, name := lookupLabel(string(val))
+		if strings.HasPrefix(name, "utf-16") {
+			return encoding.Nop, "utf-8"
+		}
+		return e, name
+	}
+	return nil, ""
+}
+
 func fromMetaElement(s string) string {
 	for s != "" {
 		csLoc := strings.Index(s, "charset")
@@ -256,6 +667,9 @@ func fromMetaElement(s string) string {
 	bom []byte
 	enc string
 }{
+	// the UTF-32LE BOM starts with the UTF-16LE one, it goes first
+	{[]byte{0xff, 0xfe, 0x00, 0x00}, "utf-32le"},
+	{[]byte{0x00, 0x00, 0xfe, 0xff}, "utf-32be"},
 	{[]byte{0xfe, 0xff}, "utf-16be"},
 	{[]byte{0xff, 0xfe}, "utf-16le"},
 	{[]byte{0xef, 0xbb, 0xbf}, "utf-8"},
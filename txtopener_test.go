@@ -1,14 +1,25 @@
 package txtopener
 
 import (
+	"errors"
+	"io"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
 )
 
 var utf8bom = []byte{0xef, 0xbb, 0xbf}
 var utf16lebom = []byte{0xff, 0xfe}
 var utf16bebom = []byte{0xfe, 0xff}
+var utf32lebom = []byte{0xff, 0xfe, 0x00, 0x00}
+var utf32bebom = []byte{0x00, 0x00, 0xfe, 0xff}
 
 func TestNewReader(t *testing.T) {
 	var tests = []struct {
@@ -28,6 +39,10 @@ func TestNewReader(t *testing.T) {
 		{string(utf8bom) + "pingüino", "pingüino"},
 		{string(utf16lebom), ""},
 		{string(utf16bebom), ""},
+		{string(utf32lebom), ""},
+		{string(utf32bebom), ""},
+		{string(utf32lebom) + "\xf1\x00\x00\x00\x00\xf6\x01\x00", "ñ😀"},
+		{string(utf32bebom) + "\x00\x00\x00\xf1\x00\x01\xf6\x00", "ñ😀"},
 	}
 
 	for i, tt := range tests {
@@ -52,3 +67,256 @@ func equalSlice(a, b []byte) bool {
 	}
 	return true
 }
+
+const fragmentMeta = `<meta content="text/html; charset=windows-1251">`
+
+func TestNewReaderFragment(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		opts     []Option
+		expected string
+	}{
+		{"<p>caf\xe9</p>" + fragmentMeta, nil, "<p>café</p>" + fragmentMeta},
+		{"<p>caf\xe9</p>" + fragmentMeta, []Option{WithFragment()}, "<p>cafй</p>" + fragmentMeta},
+		{`<script charset="windows-1251"></script>` + "\xcf\xf0\xe8", []Option{WithFragment()}, `<script charset="windows-1251"></script>При`},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderOpts(strings.NewReader(tt.feed), tt.opts...)
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("error en ReadAll: %v", err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %s -> got: %s - expected: %s", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	var tests = []struct {
+		content     string
+		contentType string
+		name        string
+		certain     bool
+	}{
+		{"\xef\xbb\xbfhola", "", "utf-8", true},
+		{"caf\xe9", "text/plain; charset=windows-1252", "windows-1252", true},
+		{`<meta charset="shift_jis">` + "\x83e\x83X\x83g", "", "shift_jis", false},
+		{"añejo", "", "utf-8", false},
+		{"\x80\xff", "text/plain; charset=x-user-defined", "x-user-defined", true},
+	}
+
+	for i, tt := range tests {
+		_, name, certain := DetectEncoding([]byte(tt.content), tt.contentType)
+		if name != tt.name || certain != tt.certain {
+			t.Errorf("%d. feeded: %q -> got: %s, %v - expected: %s, %v", i, tt.content, name, certain, tt.name, tt.certain)
+		}
+	}
+
+	if _, name, _ := DetectEncoding([]byte("caf\xe9"), "", WithProfile(WindowsLegacy)); name != "windows-1252" {
+		t.Errorf("with the WindowsLegacy profile -> got: %s - expected: windows-1252", name)
+	}
+}
+
+func TestNewReaderE(t *testing.T) {
+	failure := errors.New("connection reset")
+	if _, err := NewReaderE(iotest.ErrReader(failure)); !errors.Is(err, failure) {
+		t.Errorf("got: %v - expected: %v", err, failure)
+	}
+
+	r, err := NewReaderE(strings.NewReader("caf\xe9"))
+	if err != nil {
+		t.Fatalf("error en NewReaderE: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "café" {
+		t.Errorf("got: %q - expected: %q", got, "café")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "open.txt")
+	if err := os.WriteFile(name, append(utf16lebom, "a\x00\xf1\x00o\x00"...), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	rc, err := Open(name)
+	if err != nil {
+		t.Fatalf("error en Open: %v", err)
+	}
+	got, err := ioutil.ReadAll(rc)
+	if err != nil || string(got) != "año" {
+		t.Errorf("got: %q, %v - expected: %q", got, err, "año")
+	}
+	if err := rc.Close(); err != nil {
+		t.Errorf("error en Close: %v", err)
+	}
+	if err := rc.Close(); err == nil {
+		t.Errorf("expected an error closing the file twice")
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("expected an error opening a missing file")
+	}
+}
+
+// closeCounter counts the calls to Close
+type closeCounter struct {
+	io.Reader
+	closed int
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestNewReadCloser(t *testing.T) {
+	src := &closeCounter{Reader: strings.NewReader("caf\xe9")}
+	rc := NewReadCloser(src)
+	if got, err := ioutil.ReadAll(rc); err != nil || string(got) != "café" {
+		t.Errorf("got: %q, %v - expected: %q", got, err, "café")
+	}
+	if err := rc.Close(); err != nil || src.closed != 1 {
+		t.Errorf("Close -> got: %v, %d calls - expected 1 call", err, src.closed)
+	}
+
+	failing := &closeCounter{Reader: iotest.ErrReader(errors.New("broken"))}
+	rc = NewReadCloser(failing)
+	if _, err := ioutil.ReadAll(rc); err == nil || err.Error() != "broken" {
+		t.Errorf("got: %v - expected: broken", err)
+	}
+	rc.Close()
+	if failing.closed != 1 {
+		t.Errorf("Close -> got %d calls - expected 1", failing.closed)
+	}
+}
+
+func TestOpenAndClose(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "open.txt")
+	if err := os.WriteFile(name, []byte("caf\xe9"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	r, closer, err := OpenAndClose(name)
+	if err != nil {
+		t.Fatalf("error en OpenAndClose: %v", err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "café" {
+		t.Errorf("got: %q - expected: %q", got, "café")
+	}
+	if err := closer(); err != nil {
+		t.Errorf("error closing: %v", err)
+	}
+
+	if _, _, err := OpenAndClose(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Errorf("expected an error opening a missing file")
+	}
+}
+
+func TestNewReaderContentType(t *testing.T) {
+	var tests = []struct {
+		feed        string
+		contentType string
+		expected    string
+	}{
+		{"\xcf\xf0\xe8\xe2\xe5\xf2", "text/plain; charset=windows-1251", "Привет"},
+		{`<meta charset="koi8-r">` + "\xcf", "text/html; charset=windows-1251", `<meta charset="koi8-r">П`},
+		{string(utf8bom) + "año", "text/plain; charset=windows-1251", "año"},
+		{"caf\xe9", "text/plain", "café"},
+		{"a\x80\xff", "text/plain; charset=x-user-defined", "a\uf780\uf7ff"},
+		{`<meta charset="x-user-defined">` + "\x80", "text/html", `<meta charset="x-user-defined">` + "\uf780"},
+	}
+
+	for i, tt := range tests {
+		got, err := ioutil.ReadAll(NewReaderContentType(strings.NewReader(tt.feed), tt.contentType))
+		if err != nil {
+			t.Errorf("%d. error en ReadAll: %v", i, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestNewReaderWithEncoding(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		enc      encoding.Encoding
+		expected string
+	}{
+		{"\xcf\xf0\xe8\xe2\xe5\xf2", charmap.Windows1251, "Привет"},
+		{`<meta charset="koi8-r">` + "\xcf", charmap.Windows1251, `<meta charset="koi8-r">П`},
+		{string(utf16lebom) + "a\x00\xf1\x00", unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "añ"},
+		{string(utf8bom) + "año", encoding.Nop, "año"},
+		{"a", encoding.Nop, "a"},
+	}
+
+	for i, tt := range tests {
+		got, err := ioutil.ReadAll(NewReaderWithEncoding(strings.NewReader(tt.feed), tt.enc))
+		if err != nil {
+			t.Errorf("%d. error en ReadAll: %v", i, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestNewReaderLabel(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		label    string
+		expected string
+	}{
+		{"caf\xe9 \x80", "latin1", "café €"},
+		{"\x83e\x83X\x83g", "sjis", "テスト"},
+		{"\xcf\xf0\xe8", "cp1251", "При"},
+		{"\x80", "cp437", "Ç"},
+	}
+
+	for i, tt := range tests {
+		r, err := NewReaderLabel(tt.label, strings.NewReader(tt.feed))
+		if err != nil {
+			t.Errorf("%d. error en NewReaderLabel: %v", i, err)
+			continue
+		}
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+
+	if _, err := NewReaderLabel("no-such-encoding", strings.NewReader("")); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("got: %v - expected: %v", err, ErrUnknownEncoding)
+	}
+}
+
+func TestNewReaderResult(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+		result   Result
+	}{
+		{string(utf8bom) + "café", "café", Result{Encoding: "utf-8", BOM: "utf-8", Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{string(utf16lebom) + "\xe9\x00", "é", Result{Encoding: "utf-16le", BOM: "utf-16le", Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{string(utf16bebom) + "\x00\xe9", "é", Result{Encoding: "utf-16be", BOM: "utf-16be", Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{string(utf32lebom) + "\xe9\x00\x00\x00", "é", Result{Encoding: "utf-32le", BOM: "utf-32le", Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{string(utf32bebom) + "\x00\x00\x00\xe9", "é", Result{Encoding: "utf-32be", BOM: "utf-32be", Certain: true, Confidence: 1, Evidence: EvidenceBOM}},
+		{"caf\xe9", "café", Result{Encoding: "ISO 8859-1", Evidence: EvidenceFallback}},
+		{"", "", Result{Encoding: "ISO 8859-1", Confidence: 1, Evidence: EvidenceFallback}},
+	}
+
+	for i, tt := range tests {
+		r, res := NewReaderResult(strings.NewReader(tt.feed))
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		if res != tt.result {
+			t.Errorf("%d. feeded: %q -> got: %+v - expected: %+v", i, tt.feed, res, tt.result)
+		}
+	}
+}
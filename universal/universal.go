@@ -0,0 +1,69 @@
+// Package universal plugs a full statistical charset detector into txtopener.
+// It wraps the byte-frequency and n-gram models of github.com/saintfish/chardet (a port of
+// ICU's detector, in the spirit of Mozilla's universalchardet) as a txtopener.Detector:
+//
+//	r, err := txtopener.NewReaderOpts(f, txtopener.WithDetector(universal.New()))
+//
+// It lives in its own package because the models noticeably grow the binary, so only
+// programs that prefer accuracy over size pay for them
+package universal
+
+import (
+	"strings"
+
+	"github.com/saintfish/chardet"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// Detector is a txtopener.Detector backed by the chardet models
+type Detector struct {
+	d *chardet.Detector
+}
+
+// New returns a Detector for plain text content
+func New() *Detector {
+	return &Detector{chardet.NewTextDetector()}
+}
+
+// NewHTML returns a Detector that ignores markup when gathering statistics
+func NewHTML() *Detector {
+	return &Detector{chardet.NewHtmlDetector()}
+}
+
+// Detect implements txtopener.Detector
+func (d *Detector) Detect(preview []byte) (encoding.Encoding, string, float64) {
+	res, err := d.d.DetectBest(preview)
+	if err != nil {
+		return nil, "", 0
+	}
+	e, name := resolve(res.Charset)
+	if e == nil {
+		return nil, "", 0
+	}
+	return e, name, float64(res.Confidence) / 100
+}
+
+// resolve maps the charset names used by chardet to encodings.
+// Charsets x/text can't decode (EBCDIC, ISO-2022-CN) and those mapped to the
+// WHATWG replacement encoding aren't claimed
+func resolve(charset string) (encoding.Encoding, string) {
+	switch charset {
+	case "GB-18030":
+		charset = "gb18030"
+	case "UTF-32BE":
+		return utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM), "utf-32be"
+	case "UTF-32LE":
+		return utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM), "utf-32le"
+	}
+	e, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, ""
+	}
+	name, _ := htmlindex.Name(e)
+	if name == "replacement" {
+		return nil, ""
+	}
+	return e, strings.ToLower(name)
+}
@@ -0,0 +1,40 @@
+package universal
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/leo2904/txtopener"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestDetector(t *testing.T) {
+	var tests = []struct {
+		text string
+		feed func(string) (string, error)
+	}{
+		{"日本語のテキストファイルをUTF-8に変換します。文字化けを防ぐために、正しい文字コードを判定する必要があります。", japanese.ShiftJIS.NewEncoder().String},
+		{"Съешь же ещё этих мягких французских булок да выпей чаю. Широкая электрификация южных губерний.", charmap.Windows1251.NewEncoder().String},
+	}
+
+	for i, tt := range tests {
+		feed, err := tt.feed(tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := txtopener.NewReaderOpts(strings.NewReader(feed), txtopener.WithDetector(New()))
+		if err != nil {
+			t.Errorf("%d. error en NewReaderOpts: %v", i, err)
+			continue
+		}
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("error en ReadAll: %v", err)
+		}
+		if string(got) != tt.text {
+			t.Errorf("%d. got: %s - expected: %s", i, got, tt.text)
+		}
+	}
+}
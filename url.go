@@ -0,0 +1,82 @@
+package txtopener
+
+import (
+	"bytes"
+	"net/url"
+	"sort"
+
+	"golang.org/x/text/encoding"
+)
+
+// QueryUnescape is like url.QueryUnescape but the unescaped bytes are decoded with the encoding
+// labeled label instead of being taken for UTF-8, as legacy pages sent their forms in their own charset.
+// An empty label detects the encoding as NewReader does
+func QueryUnescape(s, label string) (string, error) {
+	raw, err := url.QueryUnescape(s)
+	if err != nil {
+		return "", err
+	}
+	return DecodeString(raw, label)
+}
+
+// PathUnescape is like QueryUnescape but, as url.PathUnescape, leaves the plus signs alone
+func PathUnescape(s, label string) (string, error) {
+	raw, err := url.PathUnescape(s)
+	if err != nil {
+		return "", err
+	}
+	return DecodeString(raw, label)
+}
+
+// ParseForm parses an application/x-www-form-urlencoded body, as url.ParseQuery does, decoding
+// the names and values with the encoding labeled label: browsers submit forms in the charset of
+// the page holding them. If label is empty the charset named by the _charset_ field, which
+// browsers fill in, is used, and when there is none it is detected from all the unescaped bytes
+// of the body together
+func ParseForm(body []byte, label string) (url.Values, error) {
+	raw, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if label == "" {
+		label = raw.Get("_charset_")
+	}
+	var e encoding.Encoding
+	if label != "" {
+		if e, _, err = lookup(label); err != nil {
+			return nil, err
+		}
+	} else {
+		keys := make([]string, 0, len(raw))
+		for k := range raw {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var all bytes.Buffer
+		for _, k := range keys {
+			all.WriteString(k + " ")
+			for _, v := range raw[k] {
+				all.WriteString(v + " ")
+			}
+		}
+		e, _, _ = determineEncoding(all.Bytes(), newOptions(nil))
+	}
+
+	d := e.NewDecoder()
+	form := make(url.Values, len(raw))
+	for k, vs := range raw {
+		name, err := d.String(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vs {
+			value, err := d.String(v)
+			if err != nil {
+				return nil, err
+			}
+			form[name] = append(form[name], value)
+		}
+	}
+	return form, nil
+}
@@ -0,0 +1,68 @@
+package txtopener
+
+import "testing"
+
+func TestQueryUnescape(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		label    string
+		expected string
+	}{
+		{"ping%C3%BCino+rey", "", "pingüino rey"},
+		{"ping%FCino+rey", "", "pingüino rey"},
+		{"%C7%D1%B1%DB", "euc-kr", "한글"},
+		{"%82%B1%82%F1", "shift_jis", "こん"},
+		{"%CF%F0%E8%E2%E5%F2", "windows-1251", "Привет"},
+	}
+
+	for i, tt := range tests {
+		got, err := QueryUnescape(tt.feed, tt.label)
+		if err != nil {
+			t.Errorf("%d. error en QueryUnescape: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %s (%s) -> got: %s - expected: %s", i, tt.feed, tt.label, got, tt.expected)
+		}
+	}
+
+	if got, err := PathUnescape("a+b%20%E9", "latin1"); err != nil || got != "a+b é" {
+		t.Errorf("PathUnescape -> got: %q, %v - expected: %q", got, err, "a+b é")
+	}
+	if _, err := QueryUnescape("%zz", ""); err == nil {
+		t.Errorf("expected an error for a malformed escape")
+	}
+	if _, err := QueryUnescape("a", "no-such-encoding"); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+}
+
+func TestParseForm(t *testing.T) {
+	var tests = []struct {
+		body     string
+		label    string
+		expected map[string]string
+	}{
+		{"nombre=ping%FCino&ciudad=M%E1laga", "", map[string]string{"nombre": "pingüino", "ciudad": "Málaga"}},
+		{"nombre=ping%C3%BCino&ciudad=M%C3%A1laga", "", map[string]string{"nombre": "pingüino", "ciudad": "Málaga"}},
+		{"q=%C7%D1%B1%DB&_charset_=euc-kr", "", map[string]string{"q": "한글", "_charset_": "euc-kr"}},
+		{"%E9=%CF%F0%E8", "windows-1251", map[string]string{"й": "При"}},
+	}
+
+	for i, tt := range tests {
+		form, err := ParseForm([]byte(tt.body), tt.label)
+		if err != nil {
+			t.Errorf("%d. error en ParseForm: %v", i, err)
+			continue
+		}
+		for k, v := range tt.expected {
+			if got := form.Get(k); got != v {
+				t.Errorf("%d. feeded: %s -> got: %s=%s - expected: %s=%s", i, tt.body, k, got, k, v)
+			}
+		}
+	}
+
+	if _, err := ParseForm([]byte("a=%zz"), ""); err == nil {
+		t.Errorf("expected an error for a malformed escape")
+	}
+}
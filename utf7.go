@@ -0,0 +1,243 @@
+package txtopener
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// utf7 is the UTF-7 encoding of RFC 2152, which writes with ASCII alone: the characters
+// outside a safe subset of it go as UTF-16 in modified Base64, between a + and an optional -
+type utf7 struct{}
+
+// NewDecoder implements encoding.Encoding
+func (utf7) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: &utf7Decoder{}}
+}
+
+// NewEncoder implements encoding.Encoding
+func (utf7) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: &utf7Encoder{}}
+}
+
+func (utf7) String() string {
+	return "utf-7"
+}
+
+// utf7Signature is the start of the UTF-7 BOM, U+FEFF. The fourth byte depends on the character
+// after it: 8, 9, + or /
+var utf7Signature = []byte("+/v")
+
+// hasUTF7Signature tells whether b starts with the UTF-7 BOM
+func hasUTF7Signature(b []byte) bool {
+	return len(b) > len(utf7Signature) && bytes.HasPrefix(b, utf7Signature) &&
+		bytes.IndexByte([]byte("89+/"), b[len(utf7Signature)]) >= 0
+}
+
+// base64Value returns the value of c in the Base64 alphabet, -1 if it isn't in it
+func base64Value(c byte) int {
+	switch {
+	case 'A' <= c && c <= 'Z':
+		return int(c - 'A')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 26
+	case '0' <= c && c <= '9':
+		return int(c-'0') + 52
+	case c == '+':
+		return 62
+	case c == '/':
+		return 63
+	}
+	return -1
+}
+
+const base64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// utf7Decoder decodes UTF-7, its state carries over from a call to the next
+type utf7Decoder struct {
+	shifted bool
+	// shift tells that the + starting the Base64 was the last byte, +- stands for +
+	shift bool
+	bits  uint32
+	nbits uint
+	// high is a high surrogate waiting for its low half
+	high rune
+}
+
+func (d *utf7Decoder) Reset() {
+	*d = utf7Decoder{}
+}
+
+func (d *utf7Decoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for ; nSrc < len(src); nSrc++ {
+		// the most a byte writes: a pending surrogate and a character
+		if len(dst)-nDst < 2*utf8.UTFMax {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		c := src[nSrc]
+		if d.shifted {
+			if v := base64Value(c); v >= 0 {
+				d.shift = false
+				d.bits, d.nbits = d.bits<<6|uint32(v), d.nbits+6
+				if d.nbits >= 16 {
+					d.nbits -= 16
+					nDst += d.unit(dst[nDst:], rune(d.bits>>d.nbits&0xffff))
+				}
+				continue
+			}
+			shift := d.shift
+			nDst += d.unshift(dst[nDst:])
+			if c == '-' {
+				if shift {
+					dst[nDst] = '+'
+					nDst++
+				}
+				continue
+			}
+		}
+		switch {
+		case c == '+':
+			d.shifted, d.shift, d.bits, d.nbits = true, true, 0, 0
+		case c < utf8.RuneSelf:
+			dst[nDst] = c
+			nDst++
+		default:
+			nDst += utf8.EncodeRune(dst[nDst:], utf8.RuneError)
+		}
+	}
+	if atEOF && d.shifted {
+		if len(dst)-nDst < 2*utf8.UTFMax {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += d.unshift(dst[nDst:])
+	}
+	return nDst, nSrc, nil
+}
+
+// unit writes the UTF-16 code unit u to dst and returns the bytes written
+func (d *utf7Decoder) unit(dst []byte, u rune) int {
+	n := 0
+	if d.high != 0 {
+		high := d.high
+		d.high = 0
+		if r := utf16.DecodeRune(high, u); r != utf8.RuneError {
+			return utf8.EncodeRune(dst, r)
+		}
+		n = utf8.EncodeRune(dst, utf8.RuneError)
+	}
+	switch {
+	case utf16.IsSurrogate(u) && u < 0xdc00:
+		d.high = u
+	case utf16.IsSurrogate(u):
+		n += utf8.EncodeRune(dst[n:], utf8.RuneError)
+	default:
+		n += utf8.EncodeRune(dst[n:], u)
+	}
+	return n
+}
+
+// unshift ends the Base64 run and returns the bytes written to dst for a surrogate left alone
+func (d *utf7Decoder) unshift(dst []byte) int {
+	d.shifted, d.shift, d.bits, d.nbits = false, false, 0, 0
+	if d.high == 0 {
+		return 0
+	}
+	d.high = 0
+	return utf8.EncodeRune(dst, utf8.RuneError)
+}
+
+// utf7Direct tells the characters UTF-7 writes as they are: the sets D and O of RFC 2152 and
+// the white space, which don't include +, \ and ~
+func utf7Direct(r rune) bool {
+	return r < utf8.RuneSelf && (r >= ' ' && r <= '}' && r != '+' && r != '\\' || r == '\t' || r == '\r' || r == '\n')
+}
+
+// utf7Encoder encodes UTF-7, its state carries over from a call to the next
+type utf7Encoder struct {
+	shifted bool
+	bits    uint32
+	nbits   uint
+}
+
+func (e *utf7Encoder) Reset() {
+	*e = utf7Encoder{}
+}
+
+// maxUTF7Rune is the most a rune writes: the end of a Base64 run or the start of one and two
+// UTF-16 code units
+const maxUTF7Rune = 10
+
+func (e *utf7Encoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if len(dst)-nDst < maxUTF7Rune {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if r == utf8.RuneError && size == 1 && !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		nSrc += size
+
+		switch {
+		case utf7Direct(r):
+			nDst += e.unshift(dst[nDst:])
+			dst[nDst] = byte(r)
+			nDst++
+		case r == '+' && !e.shifted:
+			nDst += copy(dst[nDst:], "+-")
+		default:
+			if !e.shifted {
+				dst[nDst] = '+'
+				nDst++
+				e.shifted = true
+			}
+			r1, r2 := utf16.EncodeRune(r)
+			if r1 == utf8.RuneError {
+				r1, r2 = r, -1
+			}
+			nDst += e.unit(dst[nDst:], r1)
+			if r2 >= 0 {
+				nDst += e.unit(dst[nDst:], r2)
+			}
+		}
+	}
+	if atEOF && e.shifted {
+		if len(dst)-nDst < maxUTF7Rune {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += e.unshift(dst[nDst:])
+	}
+	return nDst, nSrc, nil
+}
+
+// unit writes the UTF-16 code unit u in Base64 to dst and returns the bytes written
+func (e *utf7Encoder) unit(dst []byte, u rune) int {
+	n := 0
+	e.bits, e.nbits = e.bits<<16|uint32(u), e.nbits+16
+	for e.nbits >= 6 {
+		e.nbits -= 6
+		dst[n] = base64Alphabet[e.bits>>e.nbits&0x3f]
+		n++
+	}
+	return n
+}
+
+// unshift ends the Base64 run, if any, and returns the bytes written to dst
+func (e *utf7Encoder) unshift(dst []byte) int {
+	if !e.shifted {
+		return 0
+	}
+	n := 0
+	if e.nbits > 0 {
+		dst[n] = base64Alphabet[e.bits<<(6-e.nbits)&0x3f]
+		n++
+	}
+	// the - is needed before the characters of the alphabet and itself, and harmless before the rest
+	dst[n] = '-'
+	n++
+	e.shifted, e.bits, e.nbits = false, 0, 0
+	return n
+}
@@ -0,0 +1,74 @@
+package txtopener
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestUTF7(t *testing.T) {
+	var tests = []struct {
+		utf7 string
+		text string
+	}{
+		{"Hi Mom -+Jjo--!", "Hi Mom -☺-!"},
+		{"+ZeVnLIqe-", "日本語"},
+		{"A+ImIDkQ.", "A≢Α."},
+		{"1 +- 1 = 2", "1 + 1 = 2"},
+		{"+2D3eAA-", "😀"},
+		{"a+AFw-b+AH4-", "a\\b~"},
+		{"", ""},
+	}
+
+	for i, tt := range tests {
+		got, err := (utf7{}).NewDecoder().String(tt.utf7)
+		if err != nil || got != tt.text {
+			t.Errorf("%d. decoding: %q -> got: %q, %v - expected: %q", i, tt.utf7, got, err, tt.text)
+		}
+		encoded, err := (utf7{}).NewEncoder().String(tt.text)
+		if err != nil {
+			t.Errorf("%d. error encoding: %v", i, err)
+			continue
+		}
+		if again, _ := (utf7{}).NewDecoder().String(encoded); again != tt.text {
+			t.Errorf("%d. round trip: %q -> %q -> %q", i, tt.text, encoded, again)
+		}
+	}
+
+	// invalid input
+	if got, _ := (utf7{}).NewDecoder().String("+2D0-a\xffb"); got != "�a�b" {
+		t.Errorf("got: %q - expected: %q", got, "�a�b")
+	}
+}
+
+func TestDetectUTF7(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		expected string
+	}{
+		{"+/v8-Hi Mom -+Jjo--!", "Hi Mom -☺-!"},
+		{"+/v9l5Wcsip4-", "日本語"},
+		{"+/v8-+ADw-p+AD4-", "<p>"},
+		{"+ADw-p+AD4-", "+ADw-p+AD4-"},
+	}
+
+	for i, tt := range tests {
+		r, res := NewReaderResult(strings.NewReader(tt.feed))
+		if got, _ := ioutil.ReadAll(r); string(got) != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		if hasUTF7Signature([]byte(tt.feed)) && (res.Encoding != "utf-7" || res.BOM != "utf-7" || !res.Certain) {
+			t.Errorf("%d. feeded: %q -> got: %+v", i, tt.feed, res)
+		}
+
+		ch, ar := DetectAsync(strings.NewReader(tt.feed))
+		if got, _ := ioutil.ReadAll(ar); string(got) != tt.expected {
+			t.Errorf("%d. async feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+		<-ch
+	}
+
+	if got, err := DecodeString("+ZeVnLIqe-", "utf-7"); err != nil || got != "日本語" {
+		t.Errorf("DecodeString -> got: %q, %v - expected: %q", got, err, "日本語")
+	}
+}
@@ -0,0 +1,102 @@
+package txtopener
+
+import (
+	"bytes"
+	"io"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// lossContext is the amount of bytes of each side kept in a FirstLoss
+const lossContext = 16
+
+// FirstLoss describes the first point where a decode/encode round trip diverges from the original input
+type FirstLoss struct {
+	// Offset is the position, in bytes, of the first differing byte of the original input
+	Offset int64
+	// Original holds up to 16 bytes of the original input starting at Offset
+	Original []byte
+	// RoundTrip holds up to 16 bytes of the round-tripped output starting at Offset
+	RoundTrip []byte
+}
+
+// VerifyLossless decodes src with the encoding named by label, re-encodes the result back to that
+// same encoding and reports whether the round trip reproduces the input byte-for-byte.
+// When it doesn't, FirstLoss tells where the first divergence happened.
+// src is consumed as a stream so big files can be verified without loading them in memory
+func VerifyLossless(src io.Reader, label string) (bool, FirstLoss, error) {
+	e, _, err := lookup(label)
+	if err != nil {
+		return false, FirstLoss{}, err
+	}
+
+	var orig, got bytes.Buffer
+	rt := transform.NewReader(io.TeeReader(src, &orig),
+		transform.Chain(e.NewDecoder(), encoding.ReplaceUnsupported(e.NewEncoder())))
+
+	buf := make([]byte, 4096)
+	eof := false
+	read := func() error {
+		n, err := rt.Read(buf)
+		got.Write(buf[:n])
+		if err == io.EOF {
+			eof = true
+			return nil
+		}
+		return err
+	}
+
+	var offset int64
+	for !eof {
+		if err := read(); err != nil {
+			return false, FirstLoss{}, err
+		}
+
+		m := orig.Len()
+		if got.Len() < m {
+			m = got.Len()
+		}
+		i := mismatch(orig.Bytes()[:m], got.Bytes()[:m])
+		if i < 0 && eof && orig.Len() != got.Len() {
+			i = m
+		}
+		if i >= 0 {
+			for !eof && (orig.Len() < i+lossContext || got.Len() < i+lossContext) {
+				if err := read(); err != nil {
+					return false, FirstLoss{}, err
+				}
+			}
+			return false, FirstLoss{
+				Offset:    offset + int64(i),
+				Original:  clip(orig.Bytes()[i:], lossContext),
+				RoundTrip: clip(got.Bytes()[i:], lossContext),
+			}, nil
+		}
+		offset += int64(m)
+		orig.Next(m)
+		got.Next(m)
+	}
+	return true, FirstLoss{}, nil
+}
+
+// mismatch returns the index of the first byte where a and b differ or -1 if they are equal
+func mismatch(a, b []byte) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) != len(b) {
+		return len(a)
+	}
+	return -1
+}
+
+// clip returns a copy of at most n bytes of b
+func clip(b []byte, n int) []byte {
+	if len(b) > n {
+		b = b[:n]
+	}
+	return append([]byte(nil), b...)
+}
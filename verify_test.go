@@ -0,0 +1,38 @@
+package txtopener
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyLossless(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		label    string
+		lossless bool
+		offset   int64
+	}{
+		{"", "utf-8", true, 0},
+		{"pingüino", "utf-8", true, 0},
+		{"ping\xfcino", "windows-1252", true, 0},
+		{"ping\xfcino", "utf-8", false, 4},
+		{"abc\x81", "shift_jis", false, 3},
+		{"a\x00b\x00", "utf-16le", true, 0},
+		{"a\x00b", "utf-16le", false, 2},
+	}
+
+	for i, tt := range tests {
+		ok, loss, err := VerifyLossless(strings.NewReader(tt.feed), tt.label)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %v", i, err)
+			continue
+		}
+		if ok != tt.lossless || loss.Offset != tt.offset {
+			t.Errorf("%d. feeded: %q (%s) -> got: %v at %d - expected: %v at %d", i, tt.feed, tt.label, ok, loss.Offset, tt.lossless, tt.offset)
+		}
+	}
+
+	if _, _, err := VerifyLossless(strings.NewReader("a"), "no-such-encoding"); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+}
@@ -0,0 +1,225 @@
+package txtopener
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// NewWriter returns a writer that converts the UTF-8 text written to it to target and writes it
+// to w. Close flushes what is pending, it doesn't close w. Unless told otherwise with
+// WithBOMPolicy, a BOM at the start of the text isn't written: BOMPreserve writes it converted
+// and BOMAdd starts the output with the BOM of target, if target is a Unicode encoding.
+// The writes fail if the text holds characters target doesn't have, unless WithUnencodable tells
+// otherwise
+func NewWriter(w io.Writer, target encoding.Encoding, opts ...Option) io.WriteCloser {
+	return encodingWriter(w, target, newOptions(opts))
+}
+
+// encodingWriter returns a writer that converts UTF-8 to target as told by o
+func encodingWriter(w io.Writer, target encoding.Encoding, o *options) io.WriteCloser {
+	var t transform.Transformer = target.NewEncoder()
+	if o.unencodable != UnencodableError {
+		t = &unencodable{t: t, policy: o.unencodable}
+	}
+	if o.bomPolicy != BOMPreserve {
+		t = transform.Chain(&dropBOM{}, t)
+	}
+	if o.bomPolicy == BOMAdd {
+		if bom, err := target.NewEncoder().String(utf8BOM); err == nil {
+			w = &prefixWriter{w: w, prefix: []byte(bom)}
+		}
+	}
+	return transform.NewWriter(w, t)
+}
+
+// dropBOM is a transformer that drops a UTF-8 BOM at the start of its input
+type dropBOM struct {
+	checked bool
+}
+
+func (d *dropBOM) Reset() { d.checked = false }
+
+func (d *dropBOM) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if !d.checked {
+		if len(src) < len(utf8BOM) && !atEOF && string(src) == utf8BOM[:len(src)] {
+			return 0, 0, transform.ErrShortSrc
+		}
+		d.checked = true
+		if len(src) >= len(utf8BOM) && string(src[:len(utf8BOM)]) == utf8BOM {
+			nSrc = len(utf8BOM)
+		}
+	}
+	n := copy(dst, src[nSrc:])
+	if n < len(src)-nSrc {
+		err = transform.ErrShortDst
+	}
+	return n, nSrc + n, err
+}
+
+// prefixWriter writes prefix to w before anything else. The Close of transform.Writer writes
+// even when nothing is pending, so the prefix is written for an empty text too
+type prefixWriter struct {
+	w      io.Writer
+	prefix []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if p.prefix != nil {
+		if _, err := p.w.Write(p.prefix); err != nil {
+			return 0, err
+		}
+		p.prefix = nil
+	}
+	return p.w.Write(b)
+}
+
+// NewUTF16LEWriter returns a writer that converts the UTF-8 text written to it to UTF-16LE
+// starting with its BOM, as Windows tools such as regedit and some SQL Server loaders expect
+func NewUTF16LEWriter(w io.Writer, opts ...Option) io.WriteCloser {
+	return utf16Writer(w, unicode.LittleEndian, opts)
+}
+
+// NewUTF16BEWriter is like NewUTF16LEWriter but it writes UTF-16BE
+func NewUTF16BEWriter(w io.Writer, opts ...Option) io.WriteCloser {
+	return utf16Writer(w, unicode.BigEndian, opts)
+}
+
+func utf16Writer(w io.Writer, endianness unicode.Endianness, opts []Option) io.WriteCloser {
+	o := newOptions(opts)
+	o.bomPolicy = BOMAdd
+	return encodingWriter(w, unicode.UTF16(endianness, unicode.IgnoreBOM), o)
+}
+
+// Transcode copies src to dst converting it to the encoding labeled target, UTF-8 if empty, in a
+// single streaming pass, like iconv does. The encoding of src is detected as NewReader does and
+// its BOM removed. It returns the number of bytes written to dst and the first error found
+func Transcode(dst io.Writer, src io.Reader, target string) (int64, error) {
+	if target == "" {
+		target = "utf-8"
+	}
+	e, _, err := lookup(target)
+	if err != nil {
+		return 0, err
+	}
+	r, err := decodingReader(src, newOptions(nil))
+	if err != nil {
+		return 0, err
+	}
+
+	cw := &countingWriter{w: dst}
+	w := encodingWriter(cw, e, newOptions(nil))
+	if _, err := io.Copy(w, r); err != nil {
+		return cw.n, err
+	}
+	err = w.Close()
+	return cw.n, err
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// UnencodablePolicy tells what the writers do with the characters their target encoding doesn't have
+type UnencodablePolicy int
+
+const (
+	// UnencodableError fails the write, the default
+	UnencodableError UnencodablePolicy = iota
+	// UnencodableReplace writes a '?' instead
+	UnencodableReplace
+	// UnencodableSkip drops them
+	UnencodableSkip
+	// UnencodableEntity writes them as HTML and XML numeric character references, such as
+	// &#x3C0; for π, for markup written in legacy encodings. Invalid UTF-8 becomes &#xFFFD;
+	UnencodableEntity
+)
+
+// WithUnencodable makes the writers handle the characters their target encoding doesn't have,
+// and the invalid UTF-8 written to them, according to policy
+func WithUnencodable(policy UnencodablePolicy) Option {
+	return func(o *options) {
+		o.unencodable = policy
+	}
+}
+
+// substituteRoom is the room in dst needed to write a substitute, escape sequences included
+const substituteRoom = 32
+
+// unencodable wraps the encoder t to handle the runes it can't encode as told by policy
+type unencodable struct {
+	t      transform.Transformer
+	policy UnencodablePolicy
+}
+
+func (u *unencodable) Reset() { u.t.Reset() }
+
+func (u *unencodable) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for {
+		n, m, err := u.t.Transform(dst[nDst:], src[nSrc:], atEOF)
+		nDst, nSrc = nDst+n, nSrc+m
+		// the encoders of x/text fail with a RepertoireError on the runes they can't encode
+		if _, ok := err.(interface{ Replacement() byte }); !ok {
+			return nDst, nSrc, err
+		}
+
+		r, size := utf8.DecodeRune(src[nSrc:])
+		if u.policy == UnencodableSkip {
+			nSrc += size
+			continue
+		}
+		if len(dst)-nDst < substituteRoom {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		sub := []byte("?")
+		if u.policy == UnencodableEntity {
+			sub = []byte(fmt.Sprintf("&#x%X;", r))
+		}
+		n, _, err = u.t.Transform(dst[nDst:], sub, false)
+		if err != nil {
+			return nDst, nSrc, err
+		}
+		nDst, nSrc = nDst+n, nSrc+size
+	}
+}
+
+// NewWriterLike returns a writer that converts the UTF-8 text written to it back to the encoding
+// of the input res was detected for, starting with a BOM if the input did, and writes it to w.
+// It is meant to save the edits of a file read with NewReaderResult as it was
+func NewWriterLike(res Result, w io.Writer, opts ...Option) (io.WriteCloser, error) {
+	e, err := encodingByName(res.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	o := newOptions(opts)
+	if res.BOM != "" {
+		o.bomPolicy = BOMAdd
+	}
+	return encodingWriter(w, e, o), nil
+}
+
+// encodingByName returns the encoding named name by the detection
+func encodingByName(name string) (encoding.Encoding, error) {
+	if name == "ISO 8859-1" {
+		// the name of the fallback isn't a label, iso-8859-1 is windows-1252 for the WHATWG
+		return charmap.ISO8859_1, nil
+	}
+	if f := defaultFallback.Load(); name == f.name {
+		return f.e, nil
+	}
+	e, _, err := lookup(name)
+	return e, err
+}
@@ -0,0 +1,188 @@
+package txtopener
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestNewWriter(t *testing.T) {
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)
+	var tests = []struct {
+		feed     []string
+		target   encoding.Encoding
+		opts     []Option
+		expected string
+	}{
+		{[]string{"caf", "é"}, charmap.Windows1252, nil, "caf\xe9"},
+		{[]string{"\xef\xbb", "\xbfcafé"}, charmap.Windows1252, nil, "caf\xe9"},
+		{[]string{"テスト"}, japanese.ShiftJIS, nil, "\x83e\x83X\x83g"},
+		{[]string{"año"}, utf16le, []Option{WithBOMPolicy(BOMAdd)}, string(utf16lebom) + "a\x00\xf1\x00o\x00"},
+		{[]string{string(utf8bom) + "año"}, unicode.UTF8, []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom) + "año"},
+		{[]string{string(utf8bom) + "a"}, utf16le, []Option{WithBOMPolicy(BOMPreserve)}, string(utf16lebom) + "a\x00"},
+		{[]string{"año"}, charmap.ISO8859_1, []Option{WithBOMPolicy(BOMAdd)}, "a\xf1o"},
+		{nil, unicode.UTF8, []Option{WithBOMPolicy(BOMAdd)}, string(utf8bom)},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, tt.target, tt.opts...)
+		for _, s := range tt.feed {
+			if _, err := io.WriteString(w, s); err != nil {
+				t.Errorf("%d. error en Write: %v", i, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+
+	w := NewWriter(io.Discard, charmap.Windows1252)
+	if _, err := io.WriteString(w, "Привет"); err == nil {
+		if err = w.Close(); err == nil {
+			t.Errorf("expected an error writing characters windows-1252 doesn't have")
+		}
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		target   string
+		expected string
+	}{
+		{"caf\xe9", "", "café"},
+		{"caf\xe9", "utf-16be", "\x00c\x00a\x00f\x00\xe9"},
+		{string(utf16lebom) + "\x1f\x04@\x048\x042\x045\x04B\x04", "koi8-r", "\xf0\xd2\xc9\xd7\xc5\xd4"},
+		{string(utf8bom) + "テスト", "shift_jis", "\x83e\x83X\x83g"},
+		{strings.Repeat("caf\xe9 ", 5000), "utf-8", strings.Repeat("café ", 5000)},
+		{"", "windows-1252", ""},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		n, err := Transcode(&buf, strings.NewReader(tt.feed), tt.target)
+		if err != nil {
+			t.Errorf("%d. error en Transcode: %v", i, err)
+			continue
+		}
+		if got := buf.String(); got != tt.expected || n != int64(len(got)) {
+			t.Errorf("%d. feeded: %q -> got: %q (%d) - expected: %q", i, tt.feed, got, n, tt.expected)
+		}
+	}
+
+	if _, err := Transcode(io.Discard, strings.NewReader("a"), "no-such-encoding"); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("got: %v - expected: %v", err, ErrUnknownEncoding)
+	}
+	if _, err := Transcode(io.Discard, strings.NewReader("Привет"), "windows-1252"); err == nil {
+		t.Errorf("expected an error transcoding to an encoding without the characters")
+	}
+}
+
+func TestWithUnencodable(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		target   encoding.Encoding
+		policy   UnencodablePolicy
+		expected string
+	}{
+		{"café π", charmap.Windows1252, UnencodableReplace, "caf\xe9 ?"},
+		{"café π", charmap.Windows1252, UnencodableSkip, "caf\xe9 "},
+		{"a\xffb", charmap.ISO8859_1, UnencodableReplace, "a?b"},
+		{"ππ€", charmap.ISO8859_1, UnencodableSkip, ""},
+		{"テスト€", japanese.ShiftJIS, UnencodableReplace, "\x83e\x83X\x83g?"},
+		{"テ€ス", japanese.ISO2022JP, UnencodableReplace, "\x1b$B%F\x1b(B?\x1b$B%9\x1b(B"},
+		{strings.Repeat("π", 5000), charmap.Windows1252, UnencodableReplace, strings.Repeat("?", 5000)},
+		{"<p>café π</p>", charmap.Windows1252, UnencodableEntity, "<p>caf\xe9 &#x3C0;</p>"},
+		{"😀\xff", charmap.ISO8859_1, UnencodableEntity, "&#x1F600;&#xFFFD;"},
+		{"€π", japanese.ShiftJIS, UnencodableEntity, "&#x20AC;\x83\xce"},
+		{strings.Repeat("π", 3000), charmap.KOI8R, UnencodableEntity, strings.Repeat("&#x3C0;", 3000)},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf, tt.target, WithUnencodable(tt.policy))
+		if _, err := io.WriteString(w, tt.feed); err != nil {
+			t.Errorf("%d. error en Write: %v", i, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
+
+func TestNewWriterLike(t *testing.T) {
+	var tests = []struct {
+		feed string
+	}{
+		{"caf\xe9"},
+		{string(utf8bom) + "café"},
+		{string(utf16lebom) + "a\x00\xf1\x00o\x00"},
+		{string(utf16bebom) + "\x00a\x00\xf1\x00o"},
+		{"\x83e\x83X\x83g\x82\xcc\x83e\x83L\x83X\x83g\x82\xc5\x82\xb7\x81B"},
+		{`<meta charset="koi8-r">` + "\xf0\xd2\xc9\xd7\xc5\xd4"},
+	}
+
+	for i, tt := range tests {
+		r, res := NewReaderResult(strings.NewReader(tt.feed))
+		var buf bytes.Buffer
+		w, err := NewWriterLike(res, &buf)
+		if err != nil {
+			t.Errorf("%d. error en NewWriterLike(%+v): %v", i, res, err)
+			continue
+		}
+		if _, err := io.Copy(w, r); err != nil {
+			t.Errorf("%d. error en Copy: %v", i, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+		if got := buf.String(); got != tt.feed {
+			t.Errorf("%d. feeded: %q (%s) -> got: %q", i, tt.feed, res.Encoding, got)
+		}
+	}
+
+	if _, err := NewWriterLike(Result{Encoding: "no-such-encoding"}, io.Discard); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("got: %v - expected: %v", err, ErrUnknownEncoding)
+	}
+}
+
+func TestNewUTF16Writer(t *testing.T) {
+	var tests = []struct {
+		feed     string
+		new      func(io.Writer, ...Option) io.WriteCloser
+		expected string
+	}{
+		{"año\r\n", NewUTF16LEWriter, string(utf16lebom) + "a\x00\xf1\x00o\x00\r\x00\n\x00"},
+		{string(utf8bom) + "año", NewUTF16LEWriter, string(utf16lebom) + "a\x00\xf1\x00o\x00"},
+		{"año😀", NewUTF16BEWriter, string(utf16bebom) + "\x00a\x00\xf1\x00o\xd8\x3d\xde\x00"},
+		{"", NewUTF16BEWriter, string(utf16bebom)},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		w := tt.new(&buf)
+		if _, err := io.WriteString(w, tt.feed); err != nil {
+			t.Errorf("%d. error en Write: %v", i, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%d. error en Close: %v", i, err)
+		}
+		if got := buf.String(); got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.feed, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package txtopener
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"hash/crc32"
+	"unicode/utf8"
+)
+
+// zipUTF8Flag is the general purpose flag (the language encoding flag, or EFS) telling that the
+// name and comment of a zip entry are UTF-8
+const zipUTF8Flag = 0x800
+
+// zipUnicodePath is the id of the Info-ZIP extra field holding the UTF-8 name of an entry
+const zipUnicodePath = 0x7075
+
+// ZipName returns the name of the zip entry h as UTF-8.
+// Names flagged as UTF-8 are returned as they are. Otherwise the UTF-8 name stored by Info-ZIP
+// in the extra field is used, if it still belongs to the entry, and the raw name is decoded with
+// the encoding labeled fallback, or with CP437, the code page of the zip format, if fallback is empty
+func ZipName(h *zip.FileHeader, fallback string) (string, error) {
+	if h.Flags&zipUTF8Flag != 0 {
+		return h.Name, nil
+	}
+	if name, ok := zipExtraName(h); ok {
+		return name, nil
+	}
+	if fallback == "" {
+		fallback = "cp437"
+	}
+	e, _, err := lookup(fallback)
+	if err != nil {
+		return "", err
+	}
+	return e.NewDecoder().String(h.Name)
+}
+
+// zipExtraName looks for the Info-ZIP Unicode Path extra field of h. It holds the CRC32 of the
+// raw name it was made for so that renames by tools unaware of it can be told apart
+func zipExtraName(h *zip.FileHeader) (string, bool) {
+	extra := h.Extra
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		field := extra[:size]
+		extra = extra[size:]
+		if id != zipUnicodePath || len(field) < 5 || field[0] != 1 {
+			continue
+		}
+		name := field[5:]
+		if binary.LittleEndian.Uint32(field[1:]) == crc32.ChecksumIEEE([]byte(h.Name)) && utf8.Valid(name) {
+			return string(name), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package txtopener
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+func TestZipName(t *testing.T) {
+	unicodePath := func(raw, name string) []byte {
+		field := []byte{0x75, 0x70, 0, 0, 1, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint16(field[2:], uint16(5+len(name)))
+		binary.LittleEndian.PutUint32(field[5:], crc32.ChecksumIEEE([]byte(raw)))
+		return append(field, name...)
+	}
+
+	var tests = []struct {
+		header   zip.FileHeader
+		fallback string
+		expected string
+	}{
+		{zip.FileHeader{Name: "año.txt"}, "", "año.txt"},
+		{zip.FileHeader{Name: "a\xa4o.txt", NonUTF8: true}, "", "año.txt"},
+		{zip.FileHeader{Name: "\x8f\xa0.txt", NonUTF8: true}, "cp866", "Па.txt"},
+		{zip.FileHeader{Name: "\x83e\x83X\x83g.txt", NonUTF8: true}, "shift_jis", "テスト.txt"},
+		{zip.FileHeader{Name: "a\xa4o.txt", NonUTF8: true, Extra: unicodePath("a\xa4o.txt", "año.txt")}, "cp866", "año.txt"},
+		{zip.FileHeader{Name: "a\xa4o.txt", NonUTF8: true, Extra: unicodePath("renamed", "old.txt")}, "", "año.txt"},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		w := zip.NewWriter(&buf)
+		h := tt.header
+		if _, err := w.CreateHeader(&h); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ZipName(&r.File[0].FileHeader, tt.fallback)
+		if err != nil {
+			t.Errorf("%d. error en ZipName: %v", i, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("%d. feeded: %q -> got: %q - expected: %q", i, tt.header.Name, got, tt.expected)
+		}
+	}
+
+	if _, err := ZipName(&zip.FileHeader{Name: "a\xa4o"}, "no-such-encoding"); err == nil {
+		t.Errorf("expected an error for an unknown label")
+	}
+}